@@ -0,0 +1,351 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+// #include <curses.h>
+import "C"
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxPending limits how much of an unterminated escape sequence is held
+// back waiting for the remainder of the sequence to be written
+const maxPending = 4096
+
+// ANSIWriter is an io.Writer which prints to a window, translating ANSI SGR
+// (Select Graphic Rendition) escape sequences, as used by many programs to
+// color their output, into curses attributes and color pairs. All other
+// escape sequences and control characters, other than newline, carriage
+// return, tab and backspace, are removed.
+//
+// The writer keeps track of the current rendition and of escape sequences
+// split across calls to Write, making it suitable for the output of a
+// subprocess or logger:
+//
+// 	out := goncurses.NewANSIWriter(win)
+// 	cmd := exec.Command("ls", "--color=always")
+// 	cmd.Stdout = out
+//
+// Note that os/exec, among others, writes from a separate goroutine. See the
+// package documentation regarding concurrent use of curses. Text is written
+// as it would be by Print, so ScrollOk should be set on the window to allow
+// it to scroll once the bottom line is reached.
+type ANSIWriter struct {
+	// Refresh, if true, refreshes the window after each write
+	Refresh bool
+
+	win      *Window
+	attr     Char
+	fg, bg   int16
+	baseAttr Char
+	basePair int16
+	pending  []byte
+}
+
+// NewANSIWriter returns an ANSIWriter which writes to the window w. The
+// window's current attributes and color pair are used for text which has
+// no rendition applied and are restored by an SGR reset sequence.
+func NewANSIWriter(w *Window) *ANSIWriter {
+	attr, pair := w.Attr()
+	return &ANSIWriter{win: w, attr: Char(attr), fg: C_DEFAULT,
+		bg: C_DEFAULT, baseAttr: Char(attr), basePair: pair}
+}
+
+// Write prints p to the window, interpreting any escape sequences. An
+// incomplete escape sequence at the end of p is held back until the next
+// write. It always returns len(p) and a nil error.
+func (a *ANSIWriter) Write(p []byte) (int, error) {
+	buf := p
+	if len(a.pending) > 0 {
+		buf = append(a.pending, p...)
+		a.pending = nil
+	}
+	text := 0
+	for i := 0; i < len(buf); {
+		c := buf[i]
+		if c >= 0x20 && c != 0x7f && !isC1(buf[i:]) ||
+			c == '\n' || c == '\r' || c == '\t' || c == '\b' {
+			i++
+			continue
+		}
+		a.print(buf[text:i])
+		n := escapeLen(buf[i:])
+		if n == 0 {
+			if len(buf)-i < maxPending {
+				a.pending = append([]byte(nil), buf[i:]...)
+			}
+			a.flush()
+			return len(p), nil
+		}
+		if isSGR(buf[i : i+n]) {
+			a.sgr(string(buf[i+2 : i+n-1]))
+		}
+		i += n
+		text = i
+	}
+	// Hold back a multi-byte character split across writes
+	end := len(buf)
+	for j := end - 1; j >= text && j > end-utf8.UTFMax; j-- {
+		if utf8.RuneStart(buf[j]) {
+			if !utf8.FullRune(buf[j:]) {
+				a.pending = append([]byte(nil), buf[j:]...)
+				end = j
+			}
+			break
+		}
+	}
+	a.print(buf[text:end])
+	a.flush()
+	return len(p), nil
+}
+
+// WriteString is like Write but accepts a string
+func (a *ANSIWriter) WriteString(s string) (int, error) {
+	return a.Write([]byte(s))
+}
+
+func (a *ANSIWriter) print(text []byte) {
+	if len(text) > 0 {
+		a.win.Print(string(text))
+	}
+}
+
+func (a *ANSIWriter) flush() {
+	if a.Refresh {
+		a.win.Refresh()
+	}
+}
+
+// isC1 reports whether b begins with a UTF-8 encoded C1 control character
+func isC1(b []byte) bool {
+	return len(b) > 1 && b[0] == 0xc2 && b[1] >= 0x80 && b[1] <= 0x9f
+}
+
+// isSGR reports whether seq is a complete SGR escape sequence
+func isSGR(seq []byte) bool {
+	if len(seq) < 3 || seq[0] != 0x1b || seq[1] != '[' ||
+		seq[len(seq)-1] != 'm' {
+		return false
+	}
+	for _, c := range seq[2 : len(seq)-1] {
+		if (c < '0' || c > '9') && c != ';' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeLen returns the length of the control character or escape sequence
+// at the start of b, or zero if b ends before the sequence is complete.
+func escapeLen(b []byte) int {
+	if isC1(b) {
+		return 2
+	}
+	if b[0] != 0x1b {
+		return 1
+	}
+	if len(b) < 2 {
+		return 0
+	}
+	switch b[1] {
+	case '[': // CSI: parameters, intermediates then a final byte
+		for j := 2; j < len(b); j++ {
+			switch {
+			case b[j] >= 0x20 && b[j] <= 0x3f:
+			case b[j] >= 0x40 && b[j] <= 0x7e:
+				return j + 1
+			default: // malformed; drop what came before
+				return j
+			}
+		}
+	case ']', 'P', 'X', '^', '_': // strings terminated by BEL or ST
+		for j := 2; j < len(b); j++ {
+			if b[j] == 0x07 {
+				return j + 1
+			}
+			if b[j] == 0x1b && j+1 < len(b) && b[j+1] == '\\' {
+				return j + 2
+			}
+		}
+	default: // intermediates then a final byte
+		for j := 1; j < len(b); j++ {
+			if b[j] < 0x20 || b[j] > 0x2f {
+				return j + 1
+			}
+		}
+	}
+	return 0
+}
+
+// sgr applies the parameters of an SGR escape sequence
+func (a *ANSIWriter) sgr(params string) {
+	var vals []int
+	for _, f := range strings.Split(strings.Replace(params, ":", ";", -1),
+		";") {
+		v, _ := strconv.Atoi(f) // an empty parameter is zero
+		vals = append(vals, v)
+	}
+	for i := 0; i < len(vals); i++ {
+		switch v := vals[i]; {
+		case v == 0:
+			a.attr, a.fg, a.bg = a.baseAttr, C_DEFAULT, C_DEFAULT
+		case v == 1:
+			a.attr |= A_BOLD
+		case v == 2:
+			a.attr |= A_DIM
+		case v == 3:
+			a.attr |= A_ITALIC
+		case v == 4:
+			a.attr |= A_UNDERLINE
+		case v == 5, v == 6:
+			a.attr |= A_BLINK
+		case v == 7:
+			a.attr |= A_REVERSE
+		case v == 8:
+			a.attr |= A_INVIS
+		case v == 21, v == 22:
+			a.attr &^= A_BOLD | A_DIM
+		case v == 23:
+			a.attr &^= A_ITALIC
+		case v == 24:
+			a.attr &^= A_UNDERLINE
+		case v == 25:
+			a.attr &^= A_BLINK
+		case v == 27:
+			a.attr &^= A_REVERSE
+		case v == 28:
+			a.attr &^= A_INVIS
+		case v >= 30 && v <= 37:
+			a.fg = int16(v - 30)
+		case v >= 40 && v <= 47:
+			a.bg = int16(v - 40)
+		case v >= 90 && v <= 97:
+			a.fg = ansiColor(v - 90 + 8)
+		case v >= 100 && v <= 107:
+			a.bg = ansiColor(v - 100 + 8)
+		case v == 39:
+			a.fg = C_DEFAULT
+		case v == 49:
+			a.bg = C_DEFAULT
+		case v == 38, v == 48:
+			color, n := extendedColor(vals[i+1:])
+			i += n
+			if color == C_DEFAULT {
+				continue
+			}
+			if v == 38 {
+				a.fg = color
+			} else {
+				a.bg = color
+			}
+		}
+	}
+	pair, err := stylePair(a.basePair, a.fg, a.bg)
+	if err != nil {
+		pair = a.basePair
+	}
+	C.wattr_set(a.win.win, C.attr_t(a.attr), C.short(pair), nil)
+}
+
+// extendedColor decodes the 256 color (5;n) or direct color (2;r;g;b)
+// arguments following an SGR 38 or 48 parameter. It returns the color and
+// the number of arguments consumed.
+func extendedColor(args []int) (int16, int) {
+	if len(args) >= 2 && args[0] == 5 {
+		return ansiColor(args[1]), 2
+	}
+	if len(args) >= 4 && args[0] == 2 {
+		return rgbColor(args[1], args[2], args[3]), 4
+	}
+	return C_DEFAULT, len(args)
+}
+
+// ansiColor maps an xterm 256 color palette index onto the closest color
+// supported by the terminal
+func ansiColor(n int) int16 {
+	switch {
+	case n < 0 || n > 255:
+		return C_DEFAULT
+	case n < 8, n < int(C.COLORS):
+		return int16(n)
+	case n < 16:
+		return int16(n - 8)
+	case n < 232:
+		n -= 16
+		return rgbColor(cubeLevel(n/36), cubeLevel(n/6%6), cubeLevel(n%6))
+	}
+	grey := 8 + (n-232)*10
+	return rgbColor(grey, grey, grey)
+}
+
+// cubeLevel returns the intensity of a step in xterm's 6x6x6 color cube
+func cubeLevel(i int) int {
+	if i == 0 {
+		return 0
+	}
+	return 55 + i*40
+}
+
+// rgbColor maps a 24-bit color onto the closest color supported by the
+// terminal, using xterm's 256 color palette where available or the eight
+// basic colors otherwise.
+func rgbColor(r, g, b int) int16 {
+	if C.COLORS >= 256 {
+		level := func(v int) int {
+			if v < 48 {
+				return 0
+			}
+			if v < 115 {
+				return 1
+			}
+			return (v - 35) / 40
+		}
+		return int16(16 + 36*level(r) + 6*level(g) + level(b))
+	}
+	var color int16
+	if r > 127 {
+		color |= C_RED
+	}
+	if g > 127 {
+		color |= C_GREEN
+	}
+	if b > 127 {
+		color |= C_BLUE
+	}
+	return color
+}
+
+// windowWriters holds the ANSIWriter used by Write for each window, so that
+// the rendition and escape sequences or characters split across writes
+// carry over from one write to the next
+var windowWriters = make(map[*C.WINDOW]*ANSIWriter)
+
+// Write implements io.Writer, printing p to the window as an ANSIWriter
+// would. Each window has a single writer, created by the first write, so
+// the window may be used as the output of a subprocess whose writes are
+// split at arbitrary points. An SGR reset restores the attributes and color
+// pair the window had when first written to.
+func (w *Window) Write(p []byte) (int, error) {
+	return w.ansiWriter().Write(p)
+}
+
+// WriteString implements io.StringWriter. See Write.
+func (w *Window) WriteString(s string) (int, error) {
+	return w.ansiWriter().Write([]byte(s))
+}
+
+// ansiWriter returns the window's ANSIWriter, creating it if need be
+func (w *Window) ansiWriter() *ANSIWriter {
+	a := windowWriters[w.win]
+	if a == nil {
+		a = NewANSIWriter(w)
+		windowWriters[w.win] = a
+	}
+	return a
+}
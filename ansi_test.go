@@ -0,0 +1,77 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+// row returns the characters, with their attributes, of the first n
+// columns of the top line of win
+func row(win *goncurses.Window, n int) []goncurses.Char {
+	chars := make([]goncurses.Char, n)
+	for x := range chars {
+		chars[x] = win.MoveInChar(0, x)
+	}
+	return chars
+}
+
+func TestWindowWriteSplit(t *testing.T) {
+	goncurses.StartColor()
+	text := "\x1b[31m\xc3\xa9 ok"
+	whole, err := goncurses.NewWindow(1, 20, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer whole.Delete()
+	whole.WriteString(text)
+	want := row(whole, 10)
+
+	// Writes may split both the escape sequence and the character
+	for i := 1; i < len(text); i++ {
+		win, err := goncurses.NewWindow(1, 20, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := win.Write([]byte(text[:i])); n != i || err != nil {
+			t.Errorf("split at %d: first Write returned %d, %v", i, n, err)
+		}
+		win.Write([]byte(text[i:]))
+		for x, ch := range row(win, 10) {
+			if ch != want[x] {
+				t.Errorf("split at %d: column %d is %#x, want %#x", i, x,
+					ch, want[x])
+				break
+			}
+		}
+		win.Delete()
+	}
+}
+
+func TestWindowWriteReset(t *testing.T) {
+	if err := goncurses.StartColor(); err != nil {
+		t.Skip(err)
+	}
+	pair, err := goncurses.AllocPair(goncurses.C_GREEN, goncurses.C_BLACK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	win, err := goncurses.NewWindow(1, 20, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer win.Delete()
+	win.AttrSet(goncurses.ColorPair(pair))
+	win.WriteString("\x1b[1;31ma\x1b[0mb")
+	if got := win.MoveInChar(0, 1) &^ goncurses.A_CHARTEXT; got !=
+		goncurses.ColorPair(pair) {
+		t.Errorf("attributes after a reset are %#x, want pair %d", got, pair)
+	}
+}
@@ -0,0 +1,139 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import "errors"
+
+type BorderStyle byte
+
+// Border styles for DrawBox, DrawHLine and DrawVLine. Without wide character
+// support (see the ncursesw build tag) the double, heavy and rounded styles
+// are drawn using the single line ACS characters.
+const (
+	BORDER_SINGLE  BorderStyle = iota // ┌─┐
+	BORDER_DOUBLE                     // ╔═╗
+	BORDER_HEAVY                      // ┏━┓
+	BORDER_ROUNDED                    // ╭─╮
+	BORDER_ASCII                      // +-+
+)
+
+// Directions in which a line leaves a cell. A cell's glyph is chosen from
+// the combination of directions, allowing lines to be joined by tees and
+// crosses where they meet.
+const (
+	lineUp uint8 = 1 << iota
+	lineDown
+	lineLeft
+	lineRight
+)
+
+// lineGlyphs holds the glyph for each combination of line directions,
+// indexed by the direction bits, for each border style.
+var lineGlyphs [BORDER_ASCII + 1][16]rune
+
+var lineGlyphStrings = [...]string{
+	BORDER_SINGLE:  " │││─┘┐┤─└┌├─┴┬┼",
+	BORDER_DOUBLE:  " ║║║═╝╗╣═╚╔╠═╩╦╬",
+	BORDER_HEAVY:   " ┃┃┃━┛┓┫━┗┏┣━┻┳╋",
+	BORDER_ROUNDED: " │││─╯╮┤─╰╭├─┴┬┼",
+	BORDER_ASCII:   " |||-+++-+++-+++",
+}
+
+// acsGlyphs is the equivalent of lineGlyphs using the VT100 line drawing
+// characters
+var acsGlyphs = [16]Char{0, ACS_VLINE, ACS_VLINE, ACS_VLINE, ACS_HLINE,
+	ACS_LRCORNER, ACS_URCORNER, ACS_RTEE, ACS_HLINE, ACS_LLCORNER,
+	ACS_ULCORNER, ACS_LTEE, ACS_HLINE, ACS_BTEE, ACS_TTEE, ACS_PLUS}
+
+// glyphLines and acsLines map a glyph back onto the directions of the lines
+// it connects. A glyph used for several combinations, such as the ASCII
+// '+', maps onto all of them.
+var glyphLines = make(map[rune]uint8)
+var acsLines = make(map[Char]uint8)
+
+func init() {
+	for style, glyphs := range lineGlyphStrings {
+		copy(lineGlyphs[style][:], []rune(glyphs))
+		for mask, r := range lineGlyphs[style][1:] {
+			glyphLines[r] |= uint8(mask + 1)
+		}
+	}
+	for mask, ch := range acsGlyphs[1:] {
+		acsLines[ch] |= uint8(mask + 1)
+	}
+}
+
+// drawLines joins a line leaving the cell at y, x in the directions in mask
+// with any line already drawn there. Cells outside the window are ignored.
+func (w *Window) drawLines(y, x int, style BorderStyle, mask uint8) {
+	my, mx := w.MaxYX()
+	if y < 0 || x < 0 || y >= my || x >= mx {
+		return
+	}
+	w.setLineCell(y, x, style, mask|w.lineCell(y, x))
+}
+
+// DrawBox draws a box of the given style around the edges of the
+// rectangle. Where the box meets lines or boxes previously drawn with
+// DrawBox, DrawHLine, DrawVLine, Box or Border the appropriate tee or
+// cross characters are used to join them. The cursor is not moved.
+func (w *Window) DrawBox(r Rect, style BorderStyle) error {
+	if r.H < 2 || r.W < 2 {
+		return errors.New("Box must be at least two lines high and wide")
+	}
+	cy, cx := w.CursorYX()
+	bottom, right := r.Y+r.H-1, r.X+r.W-1
+	w.drawLines(r.Y, r.X, style, lineDown|lineRight)
+	w.drawLines(r.Y, right, style, lineDown|lineLeft)
+	w.drawLines(bottom, r.X, style, lineUp|lineRight)
+	w.drawLines(bottom, right, style, lineUp|lineLeft)
+	for x := r.X + 1; x < right; x++ {
+		w.drawLines(r.Y, x, style, lineLeft|lineRight)
+		w.drawLines(bottom, x, style, lineLeft|lineRight)
+	}
+	for y := r.Y + 1; y < bottom; y++ {
+		w.drawLines(y, r.X, style, lineUp|lineDown)
+		w.drawLines(y, right, style, lineUp|lineDown)
+	}
+	w.Move(cy, cx)
+	return nil
+}
+
+// DrawHLine draws a horizontal line n characters long starting at y, x in
+// the given style. Unlike HLine, crossings and junctions with other lines
+// are joined using tees and crosses. See DrawBox. The cursor is not moved.
+func (w *Window) DrawHLine(y, x, n int, style BorderStyle) {
+	cy, cx := w.CursorYX()
+	for i := 0; i < n; i++ {
+		var mask uint8
+		if i > 0 || n == 1 {
+			mask |= lineLeft
+		}
+		if i < n-1 || n == 1 {
+			mask |= lineRight
+		}
+		w.drawLines(y, x+i, style, mask)
+	}
+	w.Move(cy, cx)
+}
+
+// DrawVLine draws a vertical line n characters long starting at y, x in
+// the given style. Unlike VLine, crossings and junctions with other lines
+// are joined using tees and crosses. See DrawBox. The cursor is not moved.
+func (w *Window) DrawVLine(y, x, n int, style BorderStyle) {
+	cy, cx := w.CursorYX()
+	for i := 0; i < n; i++ {
+		var mask uint8
+		if i > 0 || n == 1 {
+			mask |= lineUp
+		}
+		if i < n-1 || n == 1 {
+			mask |= lineDown
+		}
+		w.drawLines(y+i, x, style, mask)
+	}
+	w.Move(cy, cx)
+}
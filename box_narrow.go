@@ -0,0 +1,30 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !ncursesw
+
+package goncurses
+
+// #include <curses.h>
+import "C"
+
+// lineCell returns the directions of the line drawn at y, x, if any
+func (w *Window) lineCell(y, x int) uint8 {
+	ch := Char(C.mvwinch(w.win, C.int(y), C.int(x)))
+	if ch&A_ALTCHARSET != 0 {
+		return acsLines[ch&(A_CHARTEXT|A_ALTCHARSET)]
+	}
+	return glyphLines[rune(ch&A_CHARTEXT)]
+}
+
+// setLineCell draws the glyph joining the lines in mask at y, x. Only the
+// ASCII style can be honoured; all other styles use the ACS characters.
+func (w *Window) setLineCell(y, x int, style BorderStyle, mask uint8) {
+	ch := acsGlyphs[mask]
+	if style == BORDER_ASCII {
+		ch = Char(lineGlyphs[BORDER_ASCII][mask])
+	}
+	C.mvwaddch(w.win, C.int(y), C.int(x), C.chtype(ch))
+}
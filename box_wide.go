@@ -0,0 +1,55 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ncursesw
+
+package goncurses
+
+/*
+#include <wchar.h>
+#include <curses.h>
+
+static int ncurses_mvwin_wch(WINDOW *win, int y, int x, wchar_t *wch,
+		attr_t *attr) {
+	cchar_t cc;
+	wchar_t wstr[CCHARW_MAX + 1];
+	short pair;
+	if (mvwin_wch(win, y, x, &cc) == ERR)
+		return ERR;
+	if (getcchar(&cc, wstr, attr, &pair, NULL) == ERR)
+		return ERR;
+	*wch = wstr[0];
+	return OK;
+}
+
+static int ncurses_mvwadd_wch(WINDOW *win, int y, int x, wchar_t wch) {
+	cchar_t cc;
+	wchar_t wstr[2] = { wch, L'\0' };
+	if (setcchar(&cc, wstr, A_NORMAL, 0, NULL) == ERR)
+		return ERR;
+	return mvwadd_wch(win, y, x, &cc);
+}
+*/
+import "C"
+
+// lineCell returns the directions of the line drawn at y, x, if any
+func (w *Window) lineCell(y, x int) uint8 {
+	var wch C.wchar_t
+	var attr C.attr_t
+	if C.ncurses_mvwin_wch(w.win, C.int(y), C.int(x), &wch, &attr) ==
+		C.ERR {
+		return 0
+	}
+	if Char(attr)&A_ALTCHARSET != 0 {
+		return acsLines[Char(wch)|A_ALTCHARSET]
+	}
+	return glyphLines[rune(wch)]
+}
+
+// setLineCell draws the glyph joining the lines in mask at y, x
+func (w *Window) setLineCell(y, x int, style BorderStyle, mask uint8) {
+	C.ncurses_mvwadd_wch(w.win, C.int(y), C.int(x),
+		C.wchar_t(lineGlyphs[style][mask]))
+}
@@ -4,8 +4,18 @@
 
 package goncurses
 
-// #cgo !windows pkg-config: ncurses
+// #cgo !windows,!ncursesw pkg-config: ncurses
+// #cgo !windows,ncursesw pkg-config: ncursesw
 // #include <curses.h>
+//
+// // Version 1 of the ncurses mouse interface has no fifth button
+// #ifndef BUTTON5_PRESSED
+// #define BUTTON5_PRESSED 0
+// #define BUTTON5_RELEASED 0
+// #define BUTTON5_CLICKED 0
+// #define BUTTON5_DOUBLE_CLICKED 0
+// #define BUTTON5_TRIPLE_CLICKED 0
+// #endif
 import "C"
 
 // Synconize options for Sync() function
@@ -31,6 +41,42 @@ const (
 	A_INVIS           = C.A_INVIS
 	A_ALTCHARSET      = C.A_ALTCHARSET
 	A_CHARTEXT        = C.A_CHARTEXT
+	A_ATTRIBUTES      = C.A_ATTRIBUTES
+	A_COLOR           = C.A_COLOR
+	A_ITALIC          = C.A_ITALIC
+	A_HORIZONTAL      = C.WA_HORIZONTAL
+	A_LEFT            = C.WA_LEFT
+	A_LOW             = C.WA_LOW
+	A_RIGHT           = C.WA_RIGHT
+	A_TOP             = C.WA_TOP
+	A_VERTICAL        = C.WA_VERTICAL
+)
+
+// Attr holds attributes of the attr_t type of curses, as used by functions
+// like ChangeAttr and Attr. Unlike a Char it holds no character.
+type Attr C.attr_t
+
+// Attributes for use with functions which operate on attr_t values rather
+// than chtype, like ChangeAttr and Attr. Not all terminals, nor curses
+// implementations, support every attribute.
+const (
+	WA_NORMAL     Attr = C.A_NORMAL
+	WA_STANDOUT   Attr = C.WA_STANDOUT
+	WA_UNDERLINE  Attr = C.WA_UNDERLINE
+	WA_REVERSE    Attr = C.WA_REVERSE
+	WA_BLINK      Attr = C.WA_BLINK
+	WA_DIM        Attr = C.WA_DIM
+	WA_BOLD       Attr = C.WA_BOLD
+	WA_ALTCHARSET Attr = C.WA_ALTCHARSET
+	WA_INVIS      Attr = C.WA_INVIS
+	WA_PROTECT    Attr = C.WA_PROTECT
+	WA_HORIZONTAL Attr = C.WA_HORIZONTAL
+	WA_LEFT       Attr = C.WA_LEFT
+	WA_LOW        Attr = C.WA_LOW
+	WA_RIGHT      Attr = C.WA_RIGHT
+	WA_TOP        Attr = C.WA_TOP
+	WA_VERTICAL   Attr = C.WA_VERTICAL
+	WA_ITALIC     Attr = C.A_ITALIC
 )
 
 var attrList = map[C.int]string{
@@ -100,6 +146,7 @@ const (
 	C_RED           = C.COLOR_RED
 	C_WHITE         = C.COLOR_WHITE
 	C_YELLOW        = C.COLOR_YELLOW
+	C_DEFAULT       = -1 // Default color; see UseDefaultColors
 )
 
 type Key int
@@ -207,6 +254,7 @@ const (
 	KEY_MOUSE         = C.KEY_MOUSE     // any mouse event
 	KEY_RESIZE        = C.KEY_RESIZE    // Terminal resize event
 	//KEY_EVENT         = C.KEY_EVENT     // We were interrupted by an event
+	KEY_MIN = C.KEY_MIN // Minimum key value is KEY_BREAK (0401)
 	KEY_MAX = C.KEY_MAX // Maximum key value is KEY_EVENT (0633)
 )
 
@@ -239,7 +287,8 @@ var keyList = map[Key]string{
 
 type MouseButton int
 
-// Mouse button events
+// Mouse button events. The button 5 events are zero, and never reported,
+// if curses was built with version 1 of the mouse interface.
 const (
 	M_ALL            MouseButton = C.ALL_MOUSE_EVENTS
 	M_ALT                        = C.BUTTON_ALT      // alt-click
@@ -263,6 +312,11 @@ const (
 	M_B4_CLICKED                 = C.BUTTON4_CLICKED
 	M_B4_DBL_CLICKED             = C.BUTTON4_DOUBLE_CLICKED
 	M_B4_TPL_CLICKED             = C.BUTTON4_TRIPLE_CLICKED
+	M_B5_PRESSED                 = C.BUTTON5_PRESSED // button 5, if any
+	M_B5_RELEASED                = C.BUTTON5_RELEASED
+	M_B5_CLICKED                 = C.BUTTON5_CLICKED
+	M_B5_DBL_CLICKED             = C.BUTTON5_DOUBLE_CLICKED
+	M_B5_TPL_CLICKED             = C.BUTTON5_TRIPLE_CLICKED
 	M_CTRL                       = C.BUTTON_CTRL           // ctrl-click
 	M_SHIFT                      = C.BUTTON_SHIFT          // shift-click
 	M_POSITION                   = C.REPORT_MOUSE_POSITION // mouse moved
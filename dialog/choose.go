@@ -0,0 +1,139 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package dialog
+
+import (
+	"errors"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+// Choose asks the user to pick one of choices from a list shown beneath
+// text, returning the index of the choice when OK is pressed or ErrCancelled
+// if the dialog is cancelled. The list scrolls if it doesn't fit on the
+// screen. The up, down, page up, page down, home and end keys and the mouse
+// wheel move through the list; double clicking on a choice picks it.
+func Choose(title, text string, choices []string) (int, error) {
+	if len(choices) == 0 {
+		return -1, errors.New("No choices given")
+	}
+	c := &choice{choices: choices}
+	d := &dialog{title: title, text: text, buttons: []string{"OK",
+		"Cancel"}, cancel: 1, body: c}
+	if _, err := d.run(); err != nil {
+		return -1, err
+	}
+	return c.current, nil
+}
+
+// choice is the body of a Choose dialog, a menu of the choices
+type choice struct {
+	choices []string
+	current int
+	r       gc.Rect
+	win     *gc.Window
+	sub     *gc.Window
+	items   []*gc.MenuItem
+	menu    *gc.Menu
+}
+
+// mark is shown beside the current choice
+const mark = "> "
+
+func (c *choice) size() (int, int) {
+	w := 0
+	for _, s := range c.choices {
+		w = max(w, gc.StringWidth(s))
+	}
+	return len(c.choices), w + len(mark)
+}
+
+func (c *choice) open(win *gc.Window, r gc.Rect) error {
+	c.items = make([]*gc.MenuItem, len(c.choices))
+	for i, s := range c.choices {
+		item, err := gc.NewItem(s, "")
+		if err != nil {
+			c.freeItems()
+			return err
+		}
+		c.items[i] = item
+	}
+	menu, err := gc.NewMenu(c.items)
+	if err != nil {
+		c.freeItems()
+		return err
+	}
+	c.r, c.win, c.menu = r, win, menu
+	c.sub = win.Derived(r.H, r.W, r.Y, r.X)
+	c.menu.SetWindow(win)
+	c.menu.SubWindow(c.sub)
+	c.menu.Format(r.H, 1)
+	c.menu.Mark(mark)
+	c.menu.Current(c.items[c.current])
+	if err := c.menu.Post(); err != nil {
+		c.close()
+		return err
+	}
+	return nil
+}
+
+func (c *choice) close() {
+	c.current = c.menu.Current(nil).Index()
+	c.menu.Close()
+	c.items = nil
+	c.sub.Delete()
+}
+
+func (c *choice) freeItems() {
+	for _, item := range c.items {
+		if item != nil {
+			item.Free()
+		}
+	}
+	c.items = nil
+}
+
+func (c *choice) key(k gc.Key) bool {
+	switch k {
+	case gc.KEY_UP:
+		c.menu.Driver(gc.REQ_UP)
+	case gc.KEY_DOWN:
+		c.menu.Driver(gc.REQ_DOWN)
+	case gc.KEY_PAGEUP:
+		c.menu.Driver(gc.REQ_PAGE_UP)
+	case gc.KEY_PAGEDOWN:
+		c.menu.Driver(gc.REQ_PAGE_DOWN)
+	case gc.KEY_HOME:
+		c.menu.Driver(gc.REQ_FIRST)
+	case gc.KEY_END:
+		c.menu.Driver(gc.REQ_LAST)
+	default:
+		return false
+	}
+	return true
+}
+
+// click makes the choice clicked on current, picking it if double clicked
+func (c *choice) click(y, x int, double bool) bool {
+	if y < c.r.Y || y >= c.r.Y+c.r.H || x < c.r.X || x >= c.r.X+c.r.W {
+		return false
+	}
+	// The current item's row within the menu gives the first item shown
+	c.menu.PositionCursor()
+	row, _ := c.sub.CursorYX()
+	i := c.menu.Current(nil).Index() - row + y - c.r.Y
+	if i < 0 || i >= len(c.items) {
+		return false
+	}
+	c.menu.Current(c.items[i])
+	return double
+}
+
+func (c *choice) focus() {
+	c.menu.PositionCursor()
+}
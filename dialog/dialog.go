@@ -0,0 +1,273 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+// Package dialog provides modal dialogs for goncurses programs: messages,
+// confirmations, text input and choosing from a list.
+//
+// Each dialog is centered on the screen in a panel placed above all others
+// and runs until it is answered, reading keys and mouse events itself.
+// Tab and the arrow keys move between buttons, Enter presses the
+// highlighted button and Escape cancels the dialog, as does pressing its
+// Cancel button. Buttons may also be clicked on if the mouse mask includes
+// button one. The dialog is laid out again if the terminal is resized.
+//
+// When a dialog closes its panel is deleted and the screen updated, so the
+// panels and windows beneath it are redrawn. The screen must have been
+// initialized, with Echo turned off, before a dialog is opened.
+package dialog
+
+import (
+	"errors"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+// ErrCancelled is returned when the user cancels a dialog
+var ErrCancelled = errors.New("Dialog cancelled")
+
+const (
+	minWidth = 24 // narrowest dialog, including its frame
+	maxWidth = 72 // widest the text of a dialog is allowed to make it
+	margin   = 2  // columns between the frame and the contents
+	keyEsc   = 27
+)
+
+// body is the part of a dialog between its text and buttons, such as an
+// input field or a list of choices
+type body interface {
+	// size returns the lines and columns the body would like
+	size() (int, int)
+	// open creates the body in the window win and area of the dialog r
+	open(win *gc.Window, r gc.Rect) error
+	// close frees the body's resources, keeping its state so that it can
+	// be opened again
+	close()
+	// key handles a key press, returning true if it was used
+	key(k gc.Key) bool
+	// click handles a click at y, x within the body's area, returning true
+	// if it should accept the dialog
+	click(y, x int, double bool) bool
+	// focus positions the cursor within the body
+	focus()
+}
+
+// dialog is an open dialog and its layout
+type dialog struct {
+	title, text string
+	buttons     []string
+	cancel      int // index of the button which cancels, or -1
+	body        body
+
+	current int // highlighted button
+	win     *gc.Window
+	panel   *gc.Panel
+	lines   []string
+	buttonY int
+	buttonX []int
+}
+
+// Message displays text in a dialog with an OK button and waits for it to
+// be dismissed. Escape also dismisses it, returning ErrCancelled.
+func Message(title, text string) error {
+	d := &dialog{title: title, text: text, buttons: []string{"OK"},
+		cancel: -1}
+	_, err := d.run()
+	return err
+}
+
+// Confirm asks a yes or no question, returning true if the user chose
+// Yes. Escape cancels the dialog, returning ErrCancelled.
+func Confirm(title, text string) (bool, error) {
+	d := &dialog{title: title, text: text, buttons: []string{"Yes", "No"},
+		cancel: -1}
+	b, err := d.run()
+	return b == 0, err
+}
+
+// run opens the dialog and handles input until a button is pressed,
+// returning the index of the button
+func (d *dialog) run() (int, error) {
+	if err := d.open(); err != nil {
+		return -1, err
+	}
+	defer d.close()
+	for {
+		d.draw()
+		k := d.win.GetChar()
+		switch k {
+		case gc.KEY_RESIZE:
+			d.close()
+			if err := d.open(); err != nil {
+				return -1, err
+			}
+			continue
+		case gc.KEY_MOUSE:
+			if b, ok := d.mouse(gc.GetMouse()); ok {
+				return d.press(b)
+			}
+			continue
+		case gc.KEY_RETURN, gc.KEY_ENTER, '\r':
+			return d.press(d.current)
+		case keyEsc:
+			return -1, ErrCancelled
+		}
+		if d.body != nil && d.body.key(k) {
+			continue
+		}
+		switch k {
+		case gc.KEY_TAB, gc.KEY_RIGHT:
+			d.current = (d.current + 1) % len(d.buttons)
+		case gc.KEY_BTAB, gc.KEY_LEFT:
+			d.current = (d.current + len(d.buttons) - 1) % len(d.buttons)
+		}
+	}
+}
+
+func (d *dialog) press(b int) (int, error) {
+	if b == d.cancel {
+		return b, ErrCancelled
+	}
+	return b, nil
+}
+
+// open lays out the dialog for the current size of the screen and creates
+// its window and panel
+func (d *dialog) open() error {
+	sh, sw := gc.StdScr().MaxYX()
+	bh, bw := 0, 0
+	if d.body != nil {
+		bh, bw = d.body.size()
+	}
+	buttonsWidth := 0
+	for _, b := range d.buttons {
+		buttonsWidth += len(b) + 5
+	}
+
+	// The dialog is as wide as its widest part, within the screen
+	w := gc.StringWidth(d.title) + 4
+	for _, line := range gc.WrapText(d.text, maxWidth-2*margin) {
+		w = max(w, gc.StringWidth(line)+2*margin)
+	}
+	w = max(w, bw+2*margin, buttonsWidth+2*margin, minWidth)
+	w = min(w, sw)
+	d.lines = gc.WrapText(d.text, w-2*margin)
+
+	// Frame, text, a blank line, the body and a blank line, then buttons.
+	// The body shrinks, then the text, if the screen is too short.
+	extra := 4
+	if d.body != nil {
+		extra++
+	}
+	if len(d.lines)+bh+extra > sh {
+		bh = max(min(bh, 1), sh-extra-len(d.lines))
+	}
+	if len(d.lines)+bh+extra > sh {
+		d.lines = d.lines[:max(0, sh-extra-bh)]
+	}
+	h := len(d.lines) + bh + extra
+	if h > sh || w < minWidth {
+		return errors.New("Screen is too small for dialog")
+	}
+
+	win, err := gc.NewWindow(h, w, (sh-h)/2, (sw-w)/2)
+	if err != nil {
+		return err
+	}
+	win.Keypad(true)
+	d.win, d.panel = win, gc.NewPanel(win)
+	d.buttonY = h - 2
+	d.buttonX = d.buttonX[:0]
+	x := (w - buttonsWidth + 1) / 2
+	for _, b := range d.buttons {
+		d.buttonX = append(d.buttonX, x)
+		x += len(b) + 5
+	}
+	if d.body != nil {
+		r := gc.Rect{Y: len(d.lines) + 2, X: margin, H: bh, W: w - 2*margin}
+		if err := d.body.open(win, r); err != nil {
+			d.close()
+			return err
+		}
+	}
+	return nil
+}
+
+// close deletes the dialog's window and panel and updates the screen to
+// show what lay beneath it
+func (d *dialog) close() {
+	if d.win == nil {
+		return
+	}
+	if d.body != nil {
+		d.body.close()
+	}
+	d.panel.Delete()
+	d.win.Delete()
+	d.win, d.panel = nil, nil
+	gc.UpdatePanels()
+	gc.Update()
+}
+
+func (d *dialog) draw() {
+	h, w := d.win.MaxYX()
+	d.win.DrawBox(gc.Rect{H: h, W: w}, gc.BORDER_SINGLE)
+	if d.title != "" {
+		d.win.MovePrint(0, margin, " "+gc.Truncate(d.title, w-6, "...")+" ")
+	}
+	for i, line := range d.lines {
+		d.win.MovePrint(i+1, margin, line)
+	}
+	for i, b := range d.buttons {
+		if i == d.current {
+			d.win.AttrOn(gc.A_REVERSE)
+		}
+		d.win.MovePrint(d.buttonY, d.buttonX[i], "< "+b+" >")
+		d.win.AttrOff(gc.A_REVERSE)
+	}
+	if d.body != nil {
+		d.body.focus()
+	} else {
+		d.win.Move(d.buttonY, d.buttonX[d.current]+2)
+	}
+	gc.UpdatePanels()
+	gc.Update()
+}
+
+// mouse handles a mouse event, returning the button clicked on, if any.
+// The first button is returned if the body accepts the dialog. Clicks
+// outside the dialog are ignored.
+func (d *dialog) mouse(ev *gc.MouseEvent) (int, bool) {
+	if ev != nil && d.body != nil && (ev.WheelUp || ev.WheelDown) {
+		if ev.WheelUp {
+			d.body.key(gc.KEY_UP)
+		} else {
+			d.body.key(gc.KEY_DOWN)
+		}
+		return 0, false
+	}
+	if ev == nil || ev.Button != 1 || ev.Action != gc.MOUSE_PRESSED &&
+		ev.Action != gc.MOUSE_CLICKED && ev.Action != gc.MOUSE_DOUBLE_CLICKED {
+		return 0, false
+	}
+	y, x, ok := d.win.MouseToLocal(ev)
+	if !ok {
+		return 0, false
+	}
+	if y == d.buttonY {
+		for i, b := range d.buttons {
+			if x >= d.buttonX[i] && x < d.buttonX[i]+len(b)+4 {
+				d.current = i
+				return i, true
+			}
+		}
+	}
+	if d.body != nil &&
+		d.body.click(y, x, ev.Action == gc.MOUSE_DOUBLE_CLICKED) {
+		return 0, true
+	}
+	return 0, false
+}
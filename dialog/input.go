@@ -0,0 +1,127 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package dialog
+
+import (
+	"strings"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+// inputWidth is the width of an input field, which scrolls horizontally
+// to accept longer values
+const inputWidth = 32
+
+// Input asks the user to enter a line of text, shown beneath prompt in an
+// editable field which initially holds value. It returns the text entered
+// when OK is pressed, or ErrCancelled if the dialog is cancelled. Tab
+// moves between the OK and Cancel buttons while the arrow, home, end,
+// backspace and delete keys edit the field; Ctrl-U clears it.
+func Input(title, prompt, value string) (string, error) {
+	in := &input{value: value}
+	d := &dialog{title: title, text: prompt, buttons: []string{"OK",
+		"Cancel"}, cancel: 1, body: in}
+	if _, err := d.run(); err != nil {
+		return "", err
+	}
+	return in.value, nil
+}
+
+// input is the body of an Input dialog, a single form field
+type input struct {
+	value string
+	r     gc.Rect
+	win   *gc.Window
+	sub   *gc.Window
+	field *gc.Field
+	form  gc.Form
+}
+
+func (in *input) size() (int, int) {
+	return 1, inputWidth
+}
+
+func (in *input) open(win *gc.Window, r gc.Rect) error {
+	field, err := gc.NewField(1, int32(r.W), 0, 0, 0, 0)
+	if err != nil {
+		return err
+	}
+	field.SetOptionsOff(gc.FO_STATIC | gc.FO_AUTOSKIP)
+	field.SetBackground(gc.A_UNDERLINE)
+	field.SetBuffer(in.value)
+	form, err := gc.NewForm([]*gc.Field{field})
+	if err != nil {
+		field.Free()
+		return err
+	}
+	in.r, in.win, in.field, in.form = r, win, field, form
+	in.sub = win.Derived(1, r.W, r.Y, r.X)
+	in.form.SetWindow(win)
+	in.form.SetSub(in.sub)
+	if err := in.form.Post(); err != nil {
+		in.close()
+		return err
+	}
+	in.form.Driver(gc.REQ_END_LINE)
+	return nil
+}
+
+func (in *input) close() {
+	in.form.Driver(gc.REQ_VALIDATION)
+	in.value = strings.TrimRight(in.field.Buffer(), " ")
+	in.form.UnPost()
+	in.form.Free()
+	in.field.Free()
+	in.sub.Delete()
+}
+
+func (in *input) key(k gc.Key) bool {
+	switch k {
+	case gc.KEY_LEFT:
+		in.form.Driver(gc.REQ_PREV_CHAR)
+	case gc.KEY_RIGHT:
+		in.form.Driver(gc.REQ_NEXT_CHAR)
+	case gc.KEY_HOME:
+		in.form.Driver(gc.REQ_BEG_LINE)
+	case gc.KEY_END:
+		in.form.Driver(gc.REQ_END_LINE)
+	case gc.KEY_BACKSPACE, 127, 8:
+		in.form.Driver(gc.REQ_DEL_PREV)
+	case gc.KEY_DC:
+		in.form.Driver(gc.REQ_DEL_CHAR)
+	case 'U' - '@':
+		in.form.Driver(gc.REQ_CLR_FIELD)
+	default:
+		// Bytes of multi-byte characters are passed on to be put together
+		// by the form library, which needs the ncursesw tag to do so
+		if k < ' ' || k > 0xff {
+			return false
+		}
+		in.form.Driver(k)
+	}
+	return true
+}
+
+// click moves the cursor to the character clicked on
+func (in *input) click(y, x int, double bool) bool {
+	if y == in.r.Y && x >= in.r.X && x < in.r.X+in.r.W {
+		_, cx := in.sub.CursorYX()
+		for ; cx < x-in.r.X; cx++ {
+			in.form.Driver(gc.REQ_NEXT_CHAR)
+		}
+		for ; cx > x-in.r.X; cx-- {
+			in.form.Driver(gc.REQ_PREV_CHAR)
+		}
+	}
+	return false
+}
+
+func (in *input) focus() {
+	y, x := in.sub.CursorYX()
+	in.win.Move(in.r.Y+y, in.r.X+x)
+}
@@ -20,3 +20,33 @@ func ExampleInit() {
 	stdscr.Print("Press enter to continue...")
 	stdscr.Refresh()
 }
+
+func ExampleSprintMarkup() {
+	runs, err := goncurses.SprintMarkup("[bold]Error:[/] [fg=red]disk [[sda] full[/]")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, run := range runs {
+		fmt.Printf("%q bold=%t fg=%d width=%d\n", run.Text,
+			run.Attr&goncurses.A_BOLD != 0, run.Fg, run.Width())
+	}
+	// Output:
+	// "Error:" bold=true fg=-1 width=6
+	// " " bold=false fg=-1 width=1
+	// "disk [sda] full" bold=false fg=1 width=15
+}
+
+func ExampleWrapText() {
+	text := "The quick brown fox jumps over the lazy dog"
+	for _, line := range goncurses.WrapText(text, 12) {
+		fmt.Printf("%q\n", line)
+	}
+	fmt.Println(goncurses.Truncate("日本語のテキスト", 9, "…"))
+	// Output:
+	// "The quick"
+	// "brown fox"
+	// "jumps over"
+	// "the lazy dog"
+	// 日本語の…
+}
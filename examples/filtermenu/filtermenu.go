@@ -0,0 +1,62 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/* Demonstrates a menu which is filtered by typing part of an item's name */
+package main
+
+import (
+	"fmt"
+	gc "github.com/rthornton128/goncurses"
+	"log"
+)
+
+func main() {
+	stdscr, err := gc.Init()
+	if err != nil {
+		log.Fatal("init:", err)
+	}
+	defer gc.End()
+
+	gc.Echo(false)
+	stdscr.Keypad(true)
+	stdscr.MovePrint(0, 0, "Type to filter the hosts, enter to choose one. "+
+		"Escape exits")
+	stdscr.Refresh()
+
+	items := make([]*gc.MenuItem, 0, 200)
+	for _, role := range []string{"web", "db", "cache", "mail", "backup"} {
+		for i := 1; i <= 40; i++ {
+			item, _ := gc.NewItem(fmt.Sprintf("%s%02d.example.com", role, i),
+				"")
+			defer item.Free()
+			items = append(items, item)
+		}
+	}
+
+	win, _ := gc.NewWindow(12, 40, 2, 2)
+	win.Keypad(true)
+	menu, err := gc.NewFilterMenu(items, win)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer menu.Free()
+	menu.Menu().OnActivate(func(item *gc.MenuItem) {
+		stdscr.Move(15, 0)
+		stdscr.ClearToEOL()
+		stdscr.Print("Chose ", item.Name())
+		stdscr.Refresh()
+	})
+	menu.Post()
+	defer menu.UnPost()
+
+	for {
+		win.Refresh()
+		ch := win.GetChar()
+		if ch == 27 {
+			return
+		}
+		menu.HandleKey(ch)
+	}
+}
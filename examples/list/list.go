@@ -0,0 +1,70 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/* Demonstrates a list with styled items, headers, separators and icons */
+package main
+
+import (
+	gc "github.com/rthornton128/goncurses"
+	"log"
+)
+
+func main() {
+	stdscr, err := gc.Init()
+	if err != nil {
+		log.Fatal("init:", err)
+	}
+	defer gc.End()
+
+	gc.Echo(false)
+	gc.Cursor(0)
+	stdscr.Keypad(true)
+	gc.MouseMask(gc.M_ALL, nil)
+	if err := gc.StartColor(); err != nil {
+		log.Fatal(err)
+	}
+	gc.InitPair(1, gc.C_RED, gc.C_BLACK)
+	gc.InitPair(2, gc.C_GREEN, gc.C_BLACK)
+	gc.InitPair(3, gc.C_CYAN, gc.C_BLACK)
+
+	stdscr.MovePrint(0, 0, "Space toggles, enter chooses, escape exits")
+	stdscr.Refresh()
+
+	items := []*gc.ListItem{
+		{Text: "Fruit", Header: true, Style: gc.Style{Pair: 3}},
+		{Text: "Äpfel", Prefix: "●", Style: gc.Style{Pair: 1}},
+		{Text: "Bananen", Prefix: "●"},
+		{Text: "Kirschen\n  small and sweet", Prefix: "●",
+			Style: gc.Style{Pair: 1}},
+		{Separator: true},
+		{Text: "Vegetables", Header: true, Style: gc.Style{Pair: 3}},
+		{Text: "Gurken", Prefix: "▲", Style: gc.Style{Pair: 2}},
+		{Text: "Möhren (sold out)", Prefix: "▲", Disabled: true},
+		{Text: "Zwiebeln", Prefix: "▲", Style: gc.Style{Attr: gc.A_BOLD}},
+	}
+
+	win, _ := gc.NewWindow(8, 30, 2, 2)
+	win.Keypad(true)
+	list := gc.NewList(items, win)
+	list.SetMultiSelect(true)
+	list.OnActivate(func(item *gc.ListItem) {
+		stdscr.Move(12, 0)
+		stdscr.ClearToEOL()
+		stdscr.Print("Chose:")
+		for _, item := range list.Selected() {
+			stdscr.Print(" ", item.Text)
+		}
+		stdscr.Refresh()
+	})
+
+	for {
+		win.Refresh()
+		ch := win.GetChar()
+		if ch == 27 {
+			return
+		}
+		list.HandleKey(ch)
+	}
+}
@@ -0,0 +1,49 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/* Demonstrates printing styled text using inline markup */
+package main
+
+import (
+	gc "github.com/rthornton128/goncurses"
+	"log"
+)
+
+func main() {
+	stdscr, err := gc.Init()
+	if err != nil {
+		log.Fatal("init:", err)
+	}
+	defer gc.End()
+
+	if err := gc.StartColor(); err != nil {
+		log.Fatal(err)
+	}
+	gc.Echo(false)
+
+	// Tags may be nested; [/] closes the most recently opened tag. Colour
+	// pairs are allocated automatically
+	stdscr.PrintMarkup("[bold]Error:[/] [fg=red]disk full[/]\n")
+	stdscr.PrintMarkup("[fg=yellow,bg=blue]Warning: [underline]low " +
+		"memory[/] detected[/]\n")
+	stdscr.PrintMarkup("Use [[ to print a literal [[bracket]\n")
+
+	// SprintMarkup can be used to measure styled text before printing it
+	runs, err := gc.SprintMarkup("[reverse] centred [/]")
+	if err != nil {
+		log.Fatal(err)
+	}
+	width := 0
+	for _, run := range runs {
+		width += run.Width()
+	}
+	_, cols := stdscr.MaxYX()
+	stdscr.Move(5, (cols-width)/2)
+	stdscr.PrintRuns(runs)
+
+	stdscr.MovePrint(7, 0, "Press any key to exit")
+	stdscr.Refresh()
+	stdscr.GetChar()
+}
@@ -0,0 +1,73 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/* Demonstrates a menu bar with drop-down menus and a cascading submenu */
+package main
+
+import (
+	gc "github.com/rthornton128/goncurses"
+	"log"
+)
+
+func main() {
+	stdscr, err := gc.Init()
+	if err != nil {
+		log.Fatal("init:", err)
+	}
+	defer gc.End()
+
+	gc.Echo(false)
+	gc.Cursor(0)
+	stdscr.Keypad(true)
+	gc.MouseMask(gc.M_B1_PRESSED|gc.M_B1_CLICKED, nil)
+
+	quit := false
+	show := func(msg string) func() {
+		return func() {
+			stdscr.Move(5, 2)
+			stdscr.ClearToEOL()
+			stdscr.Print(msg)
+			stdscr.Refresh()
+		}
+	}
+	bar, err := gc.NewMenuBar([]gc.MenuEntry{
+		{Label: "&File", Entries: []gc.MenuEntry{
+			{Label: "&New", Action: show("New file")},
+			{Label: "&Open", Action: show("Open file")},
+			{Label: "Open &Recent", Entries: []gc.MenuEntry{
+				{Label: "&1 notes.txt", Action: show("notes.txt")},
+				{Label: "&2 todo.txt", Action: show("todo.txt")},
+			}},
+			{Separator: true},
+			{Label: "&Print", Disabled: true},
+			{Label: "&Quit", Hint: "q", Action: func() { quit = true }},
+		}},
+		{Label: "&Edit", Entries: []gc.MenuEntry{
+			{Label: "Cu&t", Action: show("Cut")},
+			{Label: "&Copy", Action: show("Copy")},
+			{Label: "&Paste", Action: show("Paste")},
+		}},
+		{Label: "&About", Action: show("A goncurses menu bar")},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer bar.Delete()
+
+	stdscr.MovePrint(3, 2, "F10 or Alt and a letter opens the menus, "+
+		"q quits")
+	stdscr.Refresh()
+	for !quit {
+		bar.Refresh()
+		ch := stdscr.GetChar()
+		switch {
+		case ch == gc.KEY_MOUSE:
+			bar.HandleMouse(gc.GetMouse())
+		case bar.HandleKey(ch):
+		case ch == 'q':
+			quit = true
+		}
+	}
+}
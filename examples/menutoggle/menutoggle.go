@@ -39,7 +39,8 @@ func main() {
 	menu, _ := gc.NewMenu(items)
 	defer menu.Free()
 
-	menu.Option(gc.O_ONEVALUE, false)
+	menu.SetMultiSelect(true)
+	menu.SetCheckMarks("[x] ", "[ ] ")
 
 	y, _ := stdscr.MaxYX()
 	stdscr.MovePrint(y-3, 0, "Use up/down arrows to move, spacebar to "+
@@ -60,10 +61,8 @@ func main() {
 			menu.Driver(gc.REQ_TOGGLE)
 		case gc.KEY_RETURN, gc.KEY_ENTER:
 			var list string
-			for _, item := range menu.Items() {
-				if item.Value() {
-					list += "\"" + item.Name() + "\" "
-				}
+			for _, item := range menu.Selected() {
+				list += "\"" + item.Name() + "\" "
 			}
 			stdscr.Move(20, 0)
 			stdscr.ClearToEOL()
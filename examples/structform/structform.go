@@ -0,0 +1,86 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/* Demonstrates a form built from a struct and decoded back into it */
+package main
+
+import (
+	"fmt"
+	"log"
+
+	gc "github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/form"
+)
+
+type Config struct {
+	Host    string  `tui:"label=Host name,width=30,required"`
+	Address string  `tui:"type=ipv4"`
+	Port    int     `tui:"label=Port,width=6,min=1,max=65535"`
+	Proto   string  `tui:"label=Protocol,width=4,values=tcp|udp"`
+	Timeout float64 `tui:"label=Timeout (s),width=8,min=0"`
+	Verbose bool
+}
+
+func main() {
+	stdscr, err := gc.Init()
+	if err != nil {
+		log.Fatal("init:", err)
+	}
+	defer gc.End()
+
+	gc.Echo(false)
+	gc.CBreak(true)
+	stdscr.Keypad(true)
+
+	cfg := Config{Host: "localhost", Address: "127.0.0.1", Port: 8080,
+		Proto: "tcp", Timeout: 2.5}
+	f, err := form.FromStruct(&cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stdscr.MovePrint(0, 0, "Tab moves between fields, enter saves, "+
+		"escape exits")
+	h, w := f.Size()
+	win, _ := gc.NewWindow(h, w, 2, 2)
+	// Deferred calls run last first, so the form is freed, and removed
+	// from the window, before the window is deleted
+	defer win.Delete()
+	defer f.Free()
+	win.Keypad(true)
+	stdscr.Refresh()
+	if err := f.Post(win); err != nil {
+		log.Fatal(err)
+	}
+
+	for {
+		win.Refresh()
+		k := win.GetChar()
+		switch k {
+		case 27:
+			return
+		case gc.KEY_RETURN, gc.KEY_ENTER, '\r':
+			stdscr.Move(h+3, 0)
+			stdscr.ClearToBottom()
+			err := f.Decode(&cfg)
+			if errs, ok := err.(form.Errors); ok {
+				for i, e := range errs {
+					stdscr.MovePrint(h+3+i, 0, e)
+				}
+			} else if err != nil {
+				stdscr.MovePrint(h+3, 0, err)
+			} else {
+				stdscr.MovePrint(h+3, 0, fmt.Sprintf("Saved %+v", cfg))
+			}
+			stdscr.Refresh()
+			continue
+		}
+		if _, err := f.HandleKey(k); err != nil {
+			stdscr.MovePrint(h+3, 0, err)
+			stdscr.ClearToEOL()
+			stdscr.Refresh()
+		}
+	}
+}
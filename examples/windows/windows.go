@@ -0,0 +1,55 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/* Demonstrates overlapping windows which can be moved, resized, minimized
+ * and closed using the mouse */
+package main
+
+import (
+	gc "github.com/rthornton128/goncurses"
+	"log"
+)
+
+func main() {
+	stdscr, err := gc.Init()
+	if err != nil {
+		log.Fatal("init:", err)
+	}
+	defer gc.End()
+
+	gc.Echo(false)
+	gc.Cursor(0)
+	stdscr.Keypad(true)
+	gc.MouseMask(gc.M_ALL|gc.M_POSITION, nil)
+	gc.MouseInterval(0)
+	stdscr.MovePrint(0, 0, "Drag windows by their title bars. F6 cycles "+
+		"between windows, q quits")
+	stdscr.Refresh()
+
+	wm := gc.NewWindowManager()
+	wm.OnResize = func(mw *gc.ManagedWindow) {
+		mw.Window().MovePrint(0, 0, "Resized!")
+	}
+	for i, title := range []string{"First", "Second", "Third"} {
+		mw, err := wm.Open(title, 2+i*3, 4+i*10, 10, 30)
+		if err != nil {
+			log.Fatal(err)
+		}
+		mw.Window().Printf("This is the %s window", title)
+	}
+	wm.Refresh()
+
+	for len(wm.Windows()) > 0 {
+		switch k := stdscr.GetChar(); k {
+		case 'q':
+			return
+		case gc.KEY_MOUSE:
+			wm.HandleMouse(gc.GetMouse())
+		default:
+			wm.HandleKey(k)
+		}
+		wm.Refresh()
+	}
+}
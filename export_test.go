@@ -0,0 +1,17 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+// Internal state of the package exposed to its tests
+
+// MenuState returns the number of menu items and menus whose Go state is
+// held by the package
+func MenuState() (items, menus int) {
+	return len(menuItems), len(menuDataMap)
+}
+
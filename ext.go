@@ -29,6 +29,30 @@ var DriverActions = map[Key]int{
 	KEY_UP:       C.REQ_UP_ITEM,
 }
 
+// MenuKeys is the default keymap used by Menu.HandleKey, mapping keys to
+// menu driver requests. It may be changed to alter the keymap of all menus
+// or a menu given its own keymap with Menu.SetKeyMap.
+var MenuKeys = map[Key]int{
+	KEY_DOWN:      REQ_DOWN,
+	KEY_UP:        REQ_UP,
+	KEY_LEFT:      int(REQ_LEFT),
+	KEY_RIGHT:     REQ_RIGHT,
+	KEY_HOME:      REQ_FIRST,
+	KEY_END:       REQ_LAST,
+	KEY_PAGEDOWN:  REQ_PAGE_DOWN,
+	KEY_PAGEUP:    REQ_PAGE_UP,
+	KEY_RETURN:    REQ_ACTIVATE,
+	KEY_ENTER:     REQ_ACTIVATE,
+	'\r':          REQ_ACTIVATE,
+	' ':           REQ_TOGGLE,
+	KEY_BACKSPACE: REQ_BACK_PATTERN,
+	127:           REQ_BACK_PATTERN,  // delete, sent by many backspace keys
+	8:             REQ_BACK_PATTERN,  // ctrl-h
+	14:            REQ_NEXT_MATCH,    // ctrl-n
+	16:            REQ_PREV_MATCH,    // ctrl-p
+	21:            REQ_CLEAR_PATTERN, // ctrl-u
+}
+
 var errList = map[C.int]string{
 	C.E_SYSTEM_ERROR:    "System error occurred",
 	C.E_BAD_ARGUMENT:    "Incorrect or out-of-range argument",
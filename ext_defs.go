@@ -107,16 +107,22 @@ const (
 	REQ_BACK_PATTERN                = C.REQ_BACK_PATTERN
 	REQ_NEXT_MATCH                  = C.REQ_NEXT_MATCH
 	REQ_PREV_MATCH                  = C.REQ_PREV_MATCH
+
+	// REQ_ACTIVATE is not a request of the C library. Menu.HandleKey
+	// handles it by calling the menu's OnActivate function.
+	REQ_ACTIVATE = C.MAX_COMMAND + 1
 )
 
 // Menu Options
+type MenuOption C.Menu_Options
+
 const (
-	O_ONEVALUE   = C.O_ONEVALUE   // Only one item can be selected
-	O_SHOWDESC   = C.O_SHOWDESC   // Display item descriptions
-	O_ROWMAJOR   = C.O_ROWMAJOR   // Display in row-major order
-	O_IGNORECASE = C.O_IGNORECASE // Ingore case when pattern-matching
-	O_SHOWMATCH  = C.O_SHOWMATCH  // Move cursor to item when pattern-matching
-	O_NONCYCLIC  = C.O_NONCYCLIC  // Don't wrap next/prev item
+	O_ONEVALUE   MenuOption = C.O_ONEVALUE   // Only one item can be selected
+	O_SHOWDESC   MenuOption = C.O_SHOWDESC   // Display item descriptions
+	O_ROWMAJOR   MenuOption = C.O_ROWMAJOR   // Display in row-major order
+	O_IGNORECASE MenuOption = C.O_IGNORECASE // Ingore case when pattern-matching
+	O_SHOWMATCH  MenuOption = C.O_SHOWMATCH  // Move cursor to item when pattern-matching
+	O_NONCYCLIC  MenuOption = C.O_NONCYCLIC  // Don't wrap next/prev item
 )
 
 // Menu Item Options
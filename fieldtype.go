@@ -0,0 +1,339 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+// #include <form.h>
+// #include <stdarg.h>
+// #include <stdint.h>
+// #include <stdlib.h>
+//
+// // set_field_type is variadic, which cgo can't call, so each type has a
+// // wrapper taking its arguments
+// static int set_field_none(FIELD *f) {
+// 	return set_field_type(f, NULL);
+// }
+// static int set_field_alpha(FIELD *f, int width) {
+// 	return set_field_type(f, TYPE_ALPHA, width);
+// }
+// static int set_field_alnum(FIELD *f, int width) {
+// 	return set_field_type(f, TYPE_ALNUM, width);
+// }
+// static int set_field_enum(FIELD *f, char **values, bool checkcase,
+// 		bool checkunique) {
+// 	return set_field_type(f, TYPE_ENUM, values, checkcase, checkunique);
+// }
+// static int set_field_integer(FIELD *f, int prec, long min, long max) {
+// 	return set_field_type(f, TYPE_INTEGER, prec, min, max);
+// }
+// static int set_field_numeric(FIELD *f, int prec, double min, double max) {
+// 	return set_field_type(f, TYPE_NUMERIC, prec, min, max);
+// }
+// static int set_field_regexp(FIELD *f, char *expr) {
+// 	return set_field_type(f, TYPE_REGEXP, expr);
+// }
+// static int set_field_ipv4(FIELD *f) {
+// 	return set_field_type(f, TYPE_IPV4);
+// }
+// static int set_field_custom(FIELD *f, FIELDTYPE *type, uintptr_t h) {
+// 	return set_field_type(f, type, h);
+// }
+//
+// // The argument of a custom type is the handle of its Go FieldType, which
+// // the type's functions pass on to Go
+// extern bool goncursesFieldCheck(FIELD *, uintptr_t);
+// extern bool goncursesCharCheck(int, uintptr_t);
+// extern bool goncursesFieldNext(FIELD *, uintptr_t);
+// extern bool goncursesFieldPrev(FIELD *, uintptr_t);
+//
+// static bool field_check(FIELD *f, const void *arg) {
+// 	return goncursesFieldCheck(f, (uintptr_t)arg);
+// }
+// static bool char_check(int c, const void *arg) {
+// 	return goncursesCharCheck(c, (uintptr_t)arg);
+// }
+// static bool field_next(FIELD *f, const void *arg) {
+// 	return goncursesFieldNext(f, (uintptr_t)arg);
+// }
+// static bool field_prev(FIELD *f, const void *arg) {
+// 	return goncursesFieldPrev(f, (uintptr_t)arg);
+// }
+// static void *make_arg(va_list *ap) {
+// 	return (void *)va_arg(*ap, uintptr_t);
+// }
+// static void *copy_arg(const void *arg) {
+// 	return (void *)arg;
+// }
+// static void free_arg(void *arg) {
+// }
+// static FIELDTYPE *new_custom_fieldtype(bool check_field, bool check_char,
+// 		bool choice) {
+// 	FIELDTYPE *type = new_fieldtype(check_field ? field_check : NULL,
+// 		check_char ? char_check : NULL);
+// 	if (type == NULL)
+// 		return NULL;
+// 	if (set_fieldtype_arg(type, make_arg, copy_arg, free_arg) != E_OK ||
+// 			(choice && set_fieldtype_choice(type, field_next,
+// 				field_prev) != E_OK)) {
+// 		free_fieldtype(type);
+// 		return NULL;
+// 	}
+// 	return type;
+// }
+import "C"
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// FieldType restricts the text which may be entered into a field. The
+// text is checked when the user leaves the field and by Form.Validate.
+// Blank fields are valid as long as the field's FO_NULLOK option is on,
+// as it is by default.
+type FieldType struct {
+	set    func(f *C.FIELD) C.int
+	reason string // why text is invalid, reported by Form.Validate
+
+	// The C type and Go functions of a type made by NewFieldType
+	ctype         *C.FIELDTYPE
+	handle        uintptr
+	validateField func(string) error
+	validateChar  func(rune) bool
+	next, prev    func(string) string
+}
+
+var (
+	// fieldTypes holds the type set on each field, as the arguments of a C
+	// field type can't be read back
+	fieldTypes = make(map[*C.FIELD]*FieldType)
+
+	// fieldErrors holds the errors returned when fields were last checked
+	// by the validators of their custom types
+	fieldErrors = make(map[*C.FIELD]error)
+)
+
+// NewFieldType creates a field type whose text is checked by Go functions.
+// The text of a field, without leading and trailing blanks, is accepted if
+// validateField returns nil; the error returned otherwise is reported by
+// Form.Validate and Field.ValidationError. Each character typed into a
+// field is accepted if validateChar returns true. Either function may be
+// nil, but not both.
+//
+// The characters are checked when passed to Form.Driver. With the ncursesw
+// tag the bytes of a multi-byte character are put together so that
+// validateChar is passed the whole character. Without it the form library
+// only takes characters of a single byte, passing each as its byte value.
+//
+// The functions next and prev, if not nil, return the text following or
+// preceding a field's text in some sequence, such as the next day of a
+// date. They are called by the REQ_NEXT_CHOICE and REQ_PREV_CHOICE
+// requests and the field's text is replaced with the result.
+//
+// A type may be set on any number of fields and should be freed once no
+// field uses it.
+func NewFieldType(validateField func(string) error,
+	validateChar func(rune) bool, next, prev func(string) string) (*FieldType,
+	error) {
+	t := &FieldType{validateField: validateField, validateChar: validateChar,
+		next: next, prev: prev}
+	ctype, err := C.new_custom_fieldtype(C.bool(validateField != nil),
+		C.bool(validateChar != nil), C.bool(next != nil || prev != nil))
+	if ctype == nil {
+		return nil, ncursesError(err)
+	}
+	t.ctype, t.handle = ctype, newHandle(t)
+	t.set = func(f *C.FIELD) C.int {
+		return C.set_field_custom(f, t.ctype, C.uintptr_t(t.handle))
+	}
+	return t, nil
+}
+
+// Free releases a type made by NewFieldType. It fails if the type is still
+// set on any field. Freeing a built-in type does nothing.
+func (t *FieldType) Free() error {
+	if t.ctype == nil {
+		return nil
+	}
+	err := C.free_fieldtype(t.ctype)
+	if err != C.E_OK {
+		return ncursesError(syscall.Errno(err))
+	}
+	deleteHandle(t.handle)
+	t.ctype, t.handle = nil, 0
+	return nil
+}
+
+// TypeAlpha accepts only letters, at least width of them
+func TypeAlpha(width int) *FieldType {
+	return &FieldType{
+		set: func(f *C.FIELD) C.int {
+			return C.set_field_alpha(f, C.int(width))
+		},
+		reason: "must be letters only" + atLeast(width),
+	}
+}
+
+// TypeAlnum accepts only letters and digits, at least width of them
+func TypeAlnum(width int) *FieldType {
+	return &FieldType{
+		set: func(f *C.FIELD) C.int {
+			return C.set_field_alnum(f, C.int(width))
+		},
+		reason: "must be letters and digits only" + atLeast(width),
+	}
+}
+
+// atLeast describes the minimum width of an alpha or alnum field
+func atLeast(width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(", at least %d", width)
+}
+
+// TypeEnum accepts one of the values. A prefix of a value is accepted and
+// completed to the value when the field is left. Case is ignored unless
+// checkCase is true. If checkUnique is true a prefix must match one value
+// only.
+func TypeEnum(values []string, checkCase, checkUnique bool) *FieldType {
+	return &FieldType{
+		set: func(f *C.FIELD) C.int {
+			// The values are copied by the form library
+			size := C.size_t(unsafe.Sizeof((*C.char)(nil)))
+			p := (**C.char)(C.calloc(C.size_t(len(values)+1), size))
+			defer C.free(unsafe.Pointer(p))
+			cvalues := unsafe.Slice(p, len(values)+1)
+			for i, v := range values {
+				cvalues[i] = C.CString(v)
+				defer C.free(unsafe.Pointer(cvalues[i]))
+			}
+			return C.set_field_enum(f, p, C.bool(checkCase),
+				C.bool(checkUnique))
+		},
+		reason: "must be one of " + strings.Join(values, ", "),
+	}
+}
+
+// TypeInteger accepts a whole number from min to max. The number is padded
+// with leading zeros to precision digits when the field is left. If max is
+// not greater than min the range is not checked.
+func TypeInteger(precision int, min, max int) *FieldType {
+	reason := "must be a whole number"
+	if max > min {
+		reason += fmt.Sprintf(" from %d to %d", min, max)
+	}
+	return &FieldType{
+		set: func(f *C.FIELD) C.int {
+			return C.set_field_integer(f, C.int(precision), C.long(min),
+				C.long(max))
+		},
+		reason: reason,
+	}
+}
+
+// TypeNumeric accepts a decimal number from min to max. The number is
+// formatted with precision digits after the decimal point when the field
+// is left. If max is not greater than min the range is not checked.
+func TypeNumeric(precision int, min, max float64) *FieldType {
+	reason := "must be a number"
+	if max > min {
+		reason += fmt.Sprintf(" from %g to %g", min, max)
+	}
+	return &FieldType{
+		set: func(f *C.FIELD) C.int {
+			return C.set_field_numeric(f, C.int(precision), C.double(min),
+				C.double(max))
+		},
+		reason: reason,
+	}
+}
+
+// TypeRegexp accepts text matching the POSIX extended regular expression
+// expr. The text matched includes the padding of the field, so an anchored
+// expression should allow for trailing blanks, as in "^[a-z]+ *$".
+func TypeRegexp(expr string) *FieldType {
+	return &FieldType{
+		set: func(f *C.FIELD) C.int {
+			cexpr := C.CString(expr)
+			defer C.free(unsafe.Pointer(cexpr))
+			return C.set_field_regexp(f, cexpr)
+		},
+		reason: "must match " + expr,
+	}
+}
+
+// TypeIPv4 accepts an IPv4 address in dotted decimal notation
+func TypeIPv4() *FieldType {
+	return &FieldType{
+		set: func(f *C.FIELD) C.int {
+			return C.set_field_ipv4(f)
+		},
+		reason: "must be an IPv4 address",
+	}
+}
+
+// SetType restricts the text which may be entered into the field to that
+// accepted by the type t. A nil type removes any restriction. The field's
+// FO_PASSOK option is turned off, as otherwise the form library only checks
+// text the user has edited, passing text set by SetBuffer unchecked.
+func (f *Field) SetType(t *FieldType) error {
+	var err C.int
+	if t == nil {
+		err = C.set_field_none((*C.FIELD)(f))
+	} else {
+		err = t.set((*C.FIELD)(f))
+	}
+	if err != C.E_OK {
+		return ncursesError(syscall.Errno(err))
+	}
+	delete(fieldErrors, (*C.FIELD)(f))
+	if t == nil {
+		delete(fieldTypes, (*C.FIELD)(f))
+		return nil
+	}
+	fieldTypes[(*C.FIELD)(f)] = t
+	err = C.field_opts_off((*C.FIELD)(f), C.O_PASSOK)
+	return ncursesError(syscall.Errno(err))
+}
+
+// Type returns the type set on the field or nil if there is none
+func (f *Field) Type() *FieldType {
+	return fieldTypes[(*C.FIELD)(f)]
+}
+
+// ValidationError returns the error returned by the validator of the
+// field's custom type when the field was last checked, or nil if the text
+// was accepted. See NewFieldType.
+func (f *Field) ValidationError() error {
+	return fieldErrors[(*C.FIELD)(f)]
+}
+
+// FieldError reports a field whose text is not accepted by its type
+type FieldError struct {
+	Field  *Field
+	Index  int    // the index of the field in its form
+	Reason string // why the text was not accepted
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("Invalid field %d: %s", e.Index, e.Reason)
+}
+
+// fieldError returns the error of the invalid field f
+func fieldError(f *C.FIELD) error {
+	reason := "invalid value"
+	if err := fieldErrors[f]; err != nil {
+		reason = err.Error()
+	} else if t := fieldTypes[f]; t != nil && t.reason != "" {
+		reason = t.reason
+	}
+	return &FieldError{Field: (*Field)(f), Index: int(C.field_index(f)),
+		Reason: reason}
+}
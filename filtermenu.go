@@ -0,0 +1,370 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scores of a fuzzy match. Each character of the query matched at the start
+// of the name or of a word, or straight after the previous match, scores a
+// bonus, while each character skipped between matches costs a point.
+const (
+	fuzzyStartBonus       = 8
+	fuzzyWordBonus        = 6
+	fuzzyConsecutiveBonus = 4
+)
+
+// FilterMenu is a menu with an input line above it. As text is typed into
+// the input line, the menu shows only the items whose names contain the
+// characters of the text in order, though not necessarily together, ranked
+// by how well they match. The matched characters of each name are
+// highlighted. The current item stays current while it matches.
+//
+// The input line is the first line of the menu's window and the menu is
+// shown in a window derived from the rest. Keys are passed to HandleKey,
+// which edits the text or passes the key on to the menu's HandleKey. The
+// menu is reached through Menu to set its OnActivate function or
+// appearance, but its items must not be set except through the FilterMenu.
+type FilterMenu struct {
+	Prompt    string // shown before the text, "> " by default
+	MatchAttr Char   // attributes added to matched characters
+
+	menu    *Menu
+	win     *Window
+	sub     *Window
+	items   []*MenuItem
+	shown   []*MenuItem
+	matches map[*MenuItem][]span // the matched characters
+	query   []rune
+	partial []byte // bytes of a multi-byte character being typed
+	posted  bool
+}
+
+// span is a run of columns of an item's name
+type span struct {
+	col, width int
+}
+
+// NewFilterMenu returns a FilterMenu of the items in the window win, which
+// must be at least two lines high
+func NewFilterMenu(items []*MenuItem, win *Window) (*FilterMenu, error) {
+	menu, err := NewMenu(items)
+	if err != nil {
+		return nil, err
+	}
+	h, w := win.MaxYX()
+	f := &FilterMenu{Prompt: "> ", MatchAttr: A_BOLD | A_UNDERLINE,
+		menu: menu, win: win, sub: win.Derived(h-1, w, 1, 0)}
+	menu.SetWindow(win)
+	menu.SubWindow(f.sub)
+	menu.Format(h-1, 1)
+	menu.data(true).decorate = f.decorate
+	f.items = append(f.items, items...)
+	f.shown = f.items
+	return f, nil
+}
+
+// Menu returns the menu of items shown
+func (f *FilterMenu) Menu() *Menu {
+	return f.menu
+}
+
+// Items returns the items shown, best match first
+func (f *FilterMenu) Items() []*MenuItem {
+	return append([]*MenuItem(nil), f.shown...)
+}
+
+// Current returns the current item or nil if no item is shown
+func (f *FilterMenu) Current() *MenuItem {
+	if len(f.shown) == 0 {
+		return nil
+	}
+	cur := f.menu.Current(nil)
+	for _, item := range f.shown {
+		if item.item == cur.item {
+			return item
+		}
+	}
+	return cur
+}
+
+// Query returns the text typed into the input line
+func (f *FilterMenu) Query() string {
+	return string(f.query)
+}
+
+// SetQuery sets the text of the input line and filters the items
+func (f *FilterMenu) SetQuery(query string) error {
+	f.query, f.partial = []rune(query), nil
+	return f.filter()
+}
+
+// SetItems replaces all the items of the menu and filters them. When
+// setting items you must make sure the prior menu items will be freed.
+func (f *FilterMenu) SetItems(items []*MenuItem) error {
+	f.items = append(f.items[:0:0], items...)
+	return f.filter()
+}
+
+// Post shows the input line and the menu
+func (f *FilterMenu) Post() error {
+	f.posted = true
+	if len(f.shown) == 0 {
+		f.drawInput()
+		return nil
+	}
+	return f.menu.Post()
+}
+
+// UnPost hides the menu, erasing the window
+func (f *FilterMenu) UnPost() error {
+	f.posted = false
+	f.win.Erase()
+	if len(f.shown) == 0 {
+		return nil
+	}
+	return f.menu.UnPost()
+}
+
+// Free deallocates the menu and its derived window. The items are not
+// freed, see Close.
+func (f *FilterMenu) Free() error {
+	f.menu.data(true).decorate = nil
+	err := f.menu.Free()
+	f.sub.Delete()
+	return err
+}
+
+// Close releases the menu, unposting it first if it is posted, and then
+// frees all its items, including those filtered out
+func (f *FilterMenu) Close() error {
+	if f.posted {
+		if err := f.UnPost(); err != nil {
+			return err
+		}
+	}
+	if err := f.Free(); err != nil {
+		return err
+	}
+	var err error
+	for _, item := range f.items {
+		if e := item.Free(); e != nil && err == nil {
+			err = e
+		}
+	}
+	f.items, f.shown = nil, nil
+	return err
+}
+
+// HandleKey handles the key k, returning true if it was used. Printable
+// characters are added to the input line, backspace deletes the last
+// character and ctrl-u clears the line. Ctrl-n and ctrl-p move down and up
+// the menu. Other keys are passed to the menu's HandleKey if any item is
+// shown.
+func (f *FilterMenu) HandleKey(k Key) (bool, error) {
+	switch {
+	case k >= 0x80 && k < 0x100:
+		f.partial = append(f.partial, byte(k))
+		if !utf8.FullRune(f.partial) {
+			return true, nil
+		}
+		r, _ := utf8.DecodeRune(f.partial)
+		f.partial = nil
+		f.query = append(f.query, r)
+		return true, f.filter()
+	case k >= 0x20 && k < 0x7f:
+		f.query = append(f.query, rune(k))
+		return true, f.filter()
+	case isBackspace(k):
+		if len(f.query) > 0 {
+			f.query = f.query[:len(f.query)-1]
+		}
+		return true, f.filter()
+	case k == ctrl('U'):
+		f.query = f.query[:0]
+		return true, f.filter()
+	case len(f.shown) == 0:
+		return false, nil
+	case k == ctrl('N'):
+		return true, f.menu.Driver(REQ_DOWN)
+	case k == ctrl('P'):
+		return true, f.menu.Driver(REQ_UP)
+	}
+	return f.menu.HandleKey(k)
+}
+
+// filter ranks the items against the query and shows those which match,
+// keeping the current item if it is among them
+func (f *FilterMenu) filter() error {
+	cur := f.Current()
+	f.shown, f.matches = fuzzyFilter(f.items, f.query)
+	if f.posted && f.menu.data(true).posted {
+		if err := f.menu.UnPost(); err != nil {
+			return err
+		}
+	}
+	if len(f.shown) == 0 {
+		f.sub.Erase()
+		if f.posted {
+			f.drawInput()
+		}
+		return nil
+	}
+	if err := f.menu.SetItems(f.shown); err != nil {
+		return err
+	}
+	if cur != nil {
+		for _, item := range f.shown {
+			if item.item == cur.item {
+				f.menu.Current(item)
+				break
+			}
+		}
+	}
+	if f.posted {
+		return f.menu.Post()
+	}
+	return nil
+}
+
+// decorate highlights the matched characters of the items shown and draws
+// the input line, leaving the cursor at the end of the text
+func (f *FilterMenu) decorate() {
+	for item, spans := range f.matches {
+		for _, s := range spans {
+			f.menu.highlight(item, s.col, s.width, f.MatchAttr)
+		}
+	}
+	f.drawInput()
+}
+
+func (f *FilterMenu) drawInput() {
+	_, w := f.win.MaxYX()
+	pw := StringWidth(f.Prompt)
+	text := f.query
+	for len(text) > 0 && pw+StringWidth(string(text)) >= w {
+		text = text[1:]
+	}
+	f.win.MovePrint(0, 0, Truncate(f.Prompt, w, ""))
+	f.win.Print(string(text))
+	f.win.ClearToEOL()
+	f.win.Move(0, min(pw+StringWidth(string(text)), w-1))
+}
+
+// fuzzyFilter returns the items whose names match the query, best first,
+// and the columns of the characters matched in each name. Items which
+// match equally well keep their order. All items match an empty query.
+func fuzzyFilter(items []*MenuItem, query []rune) ([]*MenuItem,
+	map[*MenuItem][]span) {
+	if len(query) == 0 {
+		return items, nil
+	}
+	type match struct {
+		item  *MenuItem
+		score int
+	}
+	var found []match
+	spans := make(map[*MenuItem][]span)
+	for _, item := range items {
+		name := []rune(item.Name())
+		score, idx, ok := fuzzyMatch(name, query)
+		if !ok {
+			continue
+		}
+		found = append(found, match{item, score})
+		for _, i := range idx {
+			spans[item] = append(spans[item],
+				span{StringWidth(string(name[:i])), RuneWidth(name[i])})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].score > found[j].score
+	})
+	shown := make([]*MenuItem, len(found))
+	for i, m := range found {
+		shown[i] = m.item
+	}
+	return shown, spans
+}
+
+// fuzzyMatch returns the best score of the query matched, ignoring case,
+// as a subsequence of name and the indexes of the matched runes of name.
+// The value of ok is false if the query does not match.
+func fuzzyMatch(name, query []rune) (score int, idx []int, ok bool) {
+	n, m := len(name), len(query)
+	if m == 0 || m > n {
+		return 0, nil, m == 0
+	}
+	lower := make([]rune, n)
+	for j, r := range name {
+		lower[j] = unicode.ToLower(r)
+	}
+	bonus := func(j int) int {
+		switch {
+		case j == 0:
+			return fuzzyStartBonus
+		case !unicode.IsLetter(name[j-1]) && !unicode.IsDigit(name[j-1]),
+			unicode.IsLower(name[j-1]) && unicode.IsUpper(name[j]):
+			return fuzzyWordBonus
+		}
+		return 0
+	}
+
+	// best[i][j] is the best score of matching query[:i+1] with query[i]
+	// at name[j], reached from the match of query[i-1] at from[i][j]
+	const none = -1 << 30
+	best := make([][]int, m)
+	from := make([][]int, m)
+	for i := range best {
+		best[i], from[i] = make([]int, n), make([]int, n)
+		q := unicode.ToLower(query[i])
+		// The best earlier match to skip from, scored as if skipping to 0
+		skip, skipFrom := none, -1
+		for j := 0; j < n; j++ {
+			best[i][j] = none
+			if i > 0 && j >= 2 && best[i-1][j-2] != none &&
+				best[i-1][j-2]+j-1 > skip {
+				skip, skipFrom = best[i-1][j-2]+j-1, j-2
+			}
+			if lower[j] != q {
+				continue
+			}
+			if i == 0 {
+				best[i][j] = bonus(j)
+				continue
+			}
+			if skip != none {
+				best[i][j], from[i][j] = skip-j+bonus(j), skipFrom
+			}
+			if j > 0 && best[i-1][j-1] != none &&
+				best[i-1][j-1]+fuzzyConsecutiveBonus+bonus(j) > best[i][j] {
+				best[i][j] = best[i-1][j-1] + fuzzyConsecutiveBonus + bonus(j)
+				from[i][j] = j - 1
+			}
+		}
+	}
+
+	end := -1
+	for j := 0; j < n; j++ {
+		if best[m-1][j] != none && (end < 0 || best[m-1][j] > best[m-1][end]) {
+			end = j
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	idx = make([]int, m)
+	for i, j := m-1, end; i >= 0; i-- {
+		idx[i] = j
+		j = from[i][j]
+	}
+	return best[m-1][end], idx, true
+}
@@ -6,9 +6,18 @@
 
 package goncurses
 
-// #cgo pkg-config: form
+// #cgo !ncursesw pkg-config: form
+// #cgo ncursesw pkg-config: formw
 // #include <form.h>
+// #include <stdint.h>
 // #include <stdlib.h>
+//
+// static uintptr_t field_handle(const FIELD *field) {
+// 	return (uintptr_t)field_userptr(field);
+// }
+// static int set_field_handle(FIELD *field, uintptr_t h) {
+// 	return set_field_userptr(field, (void *)h);
+// }
 import "C"
 
 import (
@@ -25,7 +34,10 @@ type Form struct {
 func NewField(h, w, tr, lc, oscr, nbuf int32) (*Field, error) {
 	f, err := C.new_field(C.int(h), C.int(w), C.int(tr), C.int(lc),
 		C.int(oscr), C.int(nbuf))
-	return (*Field)(f), ncursesError(err)
+	if f == nil {
+		return nil, ncursesError(err)
+	}
+	return (*Field)(f), nil
 }
 
 // Background returns the field's background character attributes
@@ -43,10 +55,21 @@ func (f *Field) Buffer() string {
 }
 
 // Duplicate the field at the specified coordinates, returning a pointer
-// to the newly allocated object.
+// to the newly allocated object. Any user data is shared with the copy.
 func (f *Field) Duplicate(y, x int32) (*Field, error) {
 	nf, err := C.dup_field((*C.FIELD)(f), C.int(y), C.int(x))
-	return (*Field)(nf), ncursesError(err)
+	if nf == nil {
+		return nil, ncursesError(err)
+	}
+	if C.field_handle(nf) != 0 {
+		// The copy needs a handle of its own to release when it is freed
+		h := newHandle(f.UserData())
+		C.set_field_handle(nf, C.uintptr_t(h))
+	}
+	if t := f.Type(); t != nil {
+		fieldTypes[nf] = t
+	}
+	return (*Field)(nf), nil
 }
 
 // Foreground returns the field's foreground character attributes
@@ -57,7 +80,13 @@ func (f *Field) Foreground() Char {
 // Free field's allocated memory. This must be called to prevent memory
 // leaks
 func (f *Field) Free() error {
+	h := C.field_handle((*C.FIELD)(f))
 	err := C.free_field((*C.FIELD)(f))
+	if err == C.E_OK {
+		deleteHandle(uintptr(h))
+		delete(fieldTypes, (*C.FIELD)(f))
+		delete(fieldErrors, (*C.FIELD)(f))
+	}
 	f = nil
 	return ncursesError(syscall.Errno(err))
 }
@@ -156,6 +185,24 @@ func (f *Field) SetForeground(ch Char) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// SetUserData attaches the value v to the field, replacing any value
+// previously attached. The value is released when the field is freed.
+func (f *Field) SetUserData(v interface{}) error {
+	old, h := C.field_handle((*C.FIELD)(f)), newHandle(v)
+	err := C.set_field_handle((*C.FIELD)(f), C.uintptr_t(h))
+	if err != C.E_OK {
+		deleteHandle(h)
+	} else {
+		deleteHandle(uintptr(old))
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// UserData returns the value attached to the field by SetUserData or nil
+func (f *Field) UserData() interface{} {
+	return handleValue(uintptr(C.field_handle((*C.FIELD)(f))))
+}
+
 // NewForm returns a new form object using the fields array supplied as
 // an argument
 func NewForm(fields []*Field) (Form, error) {
@@ -163,8 +210,10 @@ func NewForm(fields []*Field) (Form, error) {
 		fields = append(fields, nil)
 	}
 	form, err := C.new_form((**C.FIELD)(unsafe.Pointer(&fields[0])))
-
-	return Form{form}, ncursesError(err)
+	if form == nil {
+		return Form{}, ncursesError(err)
+	}
+	return Form{form}, nil
 }
 
 // FieldCount returns the number of fields attached to the Form
@@ -173,9 +222,21 @@ func (f *Form) FieldCount() int {
 }
 
 // Driver issues the actions requested to the form itself. See the
-// corresponding REQ_* constants
+// corresponding REQ_* constants. If the text of the current field is
+// invalid, such as when leaving it, a *FieldError reporting it is returned.
+// Characters rejected by the character check of the field's type return the
+// error of an unknown command.
 func (f *Form) Driver(drvract Key) error {
-	err := C.form_driver(f.form, C.int(drvract))
+	err, ok := f.enterChar(drvract)
+	if !ok {
+		if d := f.data(false); d != nil {
+			d.partial = nil
+		}
+		err = C.form_driver(f.form, C.int(drvract))
+	}
+	if err == C.E_INVALID_FIELD {
+		return fieldError(C.current_field(f.form))
+	}
 	return ncursesError(syscall.Errno(err))
 }
 
@@ -184,12 +245,19 @@ func (f *Form) Driver(drvract Key) error {
 // it must be explicitely free'd
 func (f *Form) Free() error {
 	err := C.free_form(f.form)
+	if err == C.E_OK {
+		delete(formDataMap, f.form)
+	}
 	f = nil
 	return ncursesError(syscall.Errno(err))
 }
 
 // Post the form, making it visible and interactive
 func (f *Form) Post() error {
+	if d := f.data(false); d != nil {
+		d.posting = true
+		defer func() { d.posting = false }()
+	}
 	err := C.post_form(f.form)
 	return ncursesError(syscall.Errno(err))
 }
@@ -230,6 +298,46 @@ func (f *Form) Sub() Window {
 	return Window{C.form_sub(f.form)}
 }
 
+// Validate checks that the text of each active field is accepted by the
+// field's type, using REQ_VALIDATION, so that a form is not submitted with
+// invalid input. If a field is invalid it is made the current field and a
+// *FieldError reporting it is returned. Otherwise the current field is
+// unchanged. The form must be posted.
+func (f *Form) Validate() error {
+	cur := C.current_field(f.form)
+	if cur == nil {
+		return nil
+	}
+	if d := f.data(false); d != nil {
+		d.validating = true
+		defer d.validated(cur, C.form_page(f.form))
+	}
+	fields := unsafe.Slice(C.form_fields(f.form), C.field_count(f.form))
+	for _, field := range fields {
+		if C.field_opts(field)&(C.O_ACTIVE|C.O_VISIBLE) !=
+			C.O_ACTIVE|C.O_VISIBLE {
+			continue
+		}
+		// Leaving the current field validates it, while REQ_VALIDATION
+		// validates the field made current
+		err := C.set_current_field(f.form, field)
+		if err == C.E_INVALID_FIELD {
+			return fieldError(C.current_field(f.form))
+		}
+		if err == C.E_OK {
+			err = C.form_driver(f.form, C.REQ_VALIDATION)
+		}
+		if err == C.E_INVALID_FIELD {
+			return fieldError(field)
+		}
+		if err != C.E_OK {
+			return ncursesError(syscall.Errno(err))
+		}
+	}
+	err := C.set_current_field(f.form, cur)
+	return ncursesError(syscall.Errno(err))
+}
+
 // UnPost the form, removing it from the interface
 func (f *Form) UnPost() error {
 	err := C.unpost_form(f.form)
@@ -0,0 +1,572 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+// Package form builds goncurses forms from Go structs. Each exported field
+// of a struct becomes a labelled form field, laid out one per line, and the
+// values entered are decoded back into the struct.
+//
+// A struct field is described by its tui tag, a comma separated list of
+// options:
+//
+// 	label=Port    the label shown, by default the name of the struct field
+// 	width=6       the width of the form field in columns
+// 	type=int      the type of value accepted: string, int, uint, float,
+// 	              bool, alpha, alnum or ipv4. By default it follows the
+// 	              type of the struct field, and the text entered
+// 	              must be valid for both.
+// 	min=1,max=9   the range of an int, uint or float value
+// 	values=a|b|c  the values accepted, any other being invalid
+// 	required      a value must be entered
+//
+// A tag of "-" leaves the struct field out of the form. Struct fields may be
+// strings, booleans or numbers.
+//
+// 	type Config struct {
+// 		Host string `tui:"label=Host name,width=30,required"`
+// 		Port int    `tui:"label=Port,width=6,min=1,max=65535"`
+// 	}
+package form
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+// Widths of the form fields of each type if not given by a tag
+var defaultWidths = map[string]int{
+	"string": 20,
+	"int":    10,
+	"uint":   10,
+	"float":  12,
+	"bool":   5,
+	"alpha":  20,
+	"alnum":  20,
+	"ipv4":   15,
+}
+
+// Form is a form built from a struct by FromStruct. Keys are passed to
+// HandleKey to edit it and, once the user submits the form, Decode stores
+// the values entered in a struct.
+type Form struct {
+	form       gc.Form
+	typ        reflect.Type // of the struct the form was built from
+	fields     []*field
+	types      []*gc.FieldType // custom types to free with the form
+	labelWidth int
+	sub        *gc.Window
+	posted     bool
+}
+
+// field is a form field and the struct field it is bound to
+type field struct {
+	name, label string
+	index       int // of the struct field
+	kind        string
+	width       int
+	min, max    float64
+	ranged      bool
+	values      []string
+	required    bool
+	field       *gc.Field
+}
+
+// FieldError describes a field whose value is invalid
+type FieldError struct {
+	Name  string // the name of the struct field
+	Label string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Label + ": " + e.Err.Error()
+}
+
+// Errors holds the errors of each invalid field of a form
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// FromStruct builds a form from the struct pointed to by v, with the values
+// of its fields filled in. The form must be freed once it is no longer
+// needed.
+func FromStruct(v interface{}) (*Form, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+	rt := rv.Type()
+	f := &Form{typ: rt}
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("tui")
+		if sf.PkgPath != "" || tag == "-" {
+			continue
+		}
+		fd, err := parseTag(sf, tag)
+		if err != nil {
+			f.Free()
+			return nil, err
+		}
+		fd.index = i
+		if err := f.add(fd, rv.Field(i)); err != nil {
+			f.Free()
+			return nil, err
+		}
+	}
+	if len(f.fields) == 0 {
+		return nil, errors.New("Struct has no fields for a form")
+	}
+	fields := make([]*gc.Field, len(f.fields))
+	for i, fd := range f.fields {
+		fields[i] = fd.field
+	}
+	if f.form, err = gc.NewForm(fields); err != nil {
+		f.Free()
+		return nil, err
+	}
+	return f, nil
+}
+
+// structValue returns the struct pointed to by v
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return rv, errors.New("Form requires a pointer to a struct")
+	}
+	return rv.Elem(), nil
+}
+
+// parseTag describes the struct field sf from its tag
+func parseTag(sf reflect.StructField, tag string) (*field, error) {
+	fd := &field{name: sf.Name, label: sf.Name}
+	switch sf.Type.Kind() {
+	case reflect.String:
+		fd.kind = "string"
+	case reflect.Bool:
+		fd.kind = "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		fd.kind = "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		fd.kind = "uint"
+	case reflect.Float32, reflect.Float64:
+		fd.kind = "float"
+	default:
+		return nil, fmt.Errorf("Unsupported type of field %s: %s", sf.Name,
+			sf.Type)
+	}
+	var min, max string
+	for _, opt := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		var err error
+		switch key {
+		case "":
+		case "label":
+			fd.label = value
+		case "width":
+			fd.width, err = strconv.Atoi(value)
+		case "type":
+			if _, ok := defaultWidths[value]; !ok {
+				err = errors.New("unknown type " + value)
+			}
+			fd.kind = value
+		case "min":
+			min, fd.ranged = value, true
+			fd.min, err = strconv.ParseFloat(value, 64)
+		case "max":
+			max, fd.ranged = value, true
+			fd.max, err = strconv.ParseFloat(value, 64)
+		case "values":
+			fd.values = strings.Split(value, "|")
+		case "required":
+			fd.required = true
+		default:
+			err = errors.New("unknown option " + key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid tag of field %s: %v", sf.Name,
+				err)
+		}
+	}
+	if fd.ranged {
+		if min == "" {
+			fd.min = math.Inf(-1)
+		}
+		if max == "" {
+			fd.max = math.Inf(1)
+		}
+	}
+	if fd.width <= 0 {
+		fd.width = defaultWidths[fd.kind]
+	}
+	return fd, nil
+}
+
+// add creates the form field of fd holding the value v
+func (f *Form) add(fd *field, v reflect.Value) error {
+	gf, err := gc.NewField(1, int32(fd.width), int32(len(f.fields)), 0, 0, 0)
+	if err != nil {
+		return err
+	}
+	fd.field = gf
+	f.fields = append(f.fields, fd)
+	f.labelWidth = max(f.labelWidth, gc.StringWidth(fd.label))
+	gf.SetOptionsOff(gc.FO_AUTOSKIP)
+	gf.SetBackground(gc.A_UNDERLINE)
+	if fd.required {
+		gf.SetOptionsOff(gc.FO_NULLOK)
+	}
+
+	var typ *gc.FieldType
+	switch {
+	case fd.values != nil:
+		typ = gc.TypeEnum(fd.values, false, true)
+	case fd.kind == "alpha":
+		typ = gc.TypeAlpha(0)
+	case fd.kind == "alnum":
+		typ = gc.TypeAlnum(0)
+	case fd.kind == "ipv4":
+		typ = gc.TypeIPv4()
+	case fd.kind != "string":
+		t := v.Type()
+		typ, err = gc.NewFieldType(func(text string) error {
+			_, err := fd.value(text, t)
+			return err
+		}, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+		f.types = append(f.types, typ)
+	}
+	if typ != nil {
+		if err := gf.SetType(typ); err != nil {
+			return err
+		}
+	}
+
+	text := fmt.Sprint(v.Interface())
+	if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+		text = strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	}
+	return gf.SetBuffer(text)
+}
+
+// value converts text to a value of type t. The text must be valid both for
+// the type of the field, whatever the type of the struct field, and for t.
+func (fd *field) value(text string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if text == "" {
+		if fd.required {
+			return v, errors.New("a value is required")
+		}
+		return v, nil
+	}
+	if fd.values != nil {
+		var ok bool
+		if text, ok = fd.choose(text); !ok {
+			return v, errors.New("must be one of " +
+				strings.Join(fd.values, ", "))
+		}
+	}
+	var n float64
+	var err error
+	switch fd.kind {
+	case "bool":
+		if _, err = strconv.ParseBool(text); err != nil {
+			err = errors.New("must be true or false")
+		}
+	case "alpha":
+		if !onlyRunes(text, unicode.IsLetter) {
+			err = errors.New("must be letters only")
+		}
+	case "alnum":
+		if !onlyRunes(text, func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsDigit(r)
+		}) {
+			err = errors.New("must be letters and digits only")
+		}
+	case "ipv4":
+		if ip := net.ParseIP(text); ip == nil || ip.To4() == nil ||
+			strings.Contains(text, ":") {
+			err = errors.New("must be an IPv4 address")
+		}
+	case "int":
+		var i int64
+		i, err = strconv.ParseInt(text, 10, 64)
+		n, err = float64(i), numberError(err, "a whole number")
+	case "uint":
+		var u uint64
+		u, err = strconv.ParseUint(text, 10, 64)
+		n, err = float64(u), numberError(err, "a positive whole number")
+	case "float":
+		n, err = strconv.ParseFloat(text, 64)
+		err = numberError(err, "a number")
+	}
+	if err != nil {
+		return v, err
+	}
+	if fd.ranged && (n < fd.min || n > fd.max) {
+		switch {
+		case math.IsInf(fd.max, 1):
+			return v, fmt.Errorf("must be at least %v", fd.min)
+		case math.IsInf(fd.min, -1):
+			return v, fmt.Errorf("must be at most %v", fd.max)
+		}
+		return v, fmt.Errorf("must be from %v to %v", fd.min, fd.max)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(text); err != nil {
+			err = errors.New("must be true or false")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(text, 10, t.Bits())
+		v.SetInt(i)
+		err = numberError(err, "a whole number")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		var u uint64
+		u, err = strconv.ParseUint(text, 10, t.Bits())
+		v.SetUint(u)
+		err = numberError(err, "a positive whole number")
+	case reflect.Float32, reflect.Float64:
+		var x float64
+		x, err = strconv.ParseFloat(text, t.Bits())
+		v.SetFloat(x)
+		err = numberError(err, "a number")
+	}
+	return v, err
+}
+
+// choose returns the value of the field which text names, ignoring case. A
+// prefix of one value only also names it, as the form library completes
+// such a prefix to the value when the field is left.
+func (fd *field) choose(text string) (string, bool) {
+	match := ""
+	for _, value := range fd.values {
+		if strings.EqualFold(value, text) {
+			return value, true
+		}
+		if len(value) > len(text) &&
+			strings.EqualFold(value[:len(text)], text) {
+			if match != "" {
+				return "", false
+			}
+			match = value
+		}
+	}
+	return match, match != ""
+}
+
+// onlyRunes returns true if every rune of s satisfies f
+func onlyRunes(s string, f func(rune) bool) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return !f(r) }) < 0
+}
+
+// numberError explains the error of parsing a number, if any
+func numberError(err error, what string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return errors.New("is out of range")
+	}
+	return errors.New("must be " + what)
+}
+
+// Form returns the underlying goncurses form, to set hooks or make driver
+// requests
+func (f *Form) Form() *gc.Form {
+	return &f.form
+}
+
+// Size returns the lines and columns taken by the form
+func (f *Form) Size() (int, int) {
+	w := 0
+	for _, fd := range f.fields {
+		w = max(w, fd.width)
+	}
+	return len(f.fields), f.labelWidth + 2 + w
+}
+
+// Post draws the labels of the form in the window win, beginning at its top
+// left corner, and posts the form beside them
+func (f *Form) Post(win *gc.Window) error {
+	h, w := f.Size()
+	if wh, ww := win.MaxYX(); h > wh || w > ww {
+		return errors.New("Window is too small for form")
+	}
+	for i, fd := range f.fields {
+		win.MovePrint(i, 0, fd.label+":")
+	}
+	f.sub = win.Derived(h, w-f.labelWidth-2, 0, f.labelWidth+2)
+	f.form.SetWindow(win)
+	f.form.SetSub(f.sub)
+	if err := f.form.Post(); err != nil {
+		f.sub.Delete()
+		f.sub = nil
+		return err
+	}
+	f.posted = true
+	return f.form.Driver(gc.REQ_END_LINE)
+}
+
+// UnPost removes the form from its window
+func (f *Form) UnPost() error {
+	if !f.posted {
+		return nil
+	}
+	f.posted = false
+	err := f.form.UnPost()
+	f.sub.Delete()
+	f.sub = nil
+	return err
+}
+
+// Free unposts the form and frees its fields
+func (f *Form) Free() error {
+	err := f.UnPost()
+	if f.form != (gc.Form{}) {
+		if e := f.form.Free(); err == nil {
+			err = e
+		}
+	}
+	for _, fd := range f.fields {
+		fd.field.Free()
+	}
+	for _, t := range f.types {
+		t.Free()
+	}
+	f.fields, f.types = nil, nil
+	return err
+}
+
+// HandleKey edits the form in response to the key k, returning true if it
+// was used. Tab and the up and down keys move between fields while the
+// left, right, home, end, backspace and delete keys edit the current field;
+// Ctrl-U clears it. Other characters are entered into the field, including
+// the bytes of multi-byte characters as returned by GetChar. The error is
+// that of the form driver, or a *FieldError if the value of the field being
+// left is invalid.
+func (f *Form) HandleKey(k gc.Key) (bool, error) {
+	var reqs []gc.Key
+	switch k {
+	case gc.KEY_TAB, gc.KEY_DOWN:
+		reqs = []gc.Key{gc.REQ_NEXT_FIELD, gc.REQ_END_LINE}
+	case gc.KEY_BTAB, gc.KEY_UP:
+		reqs = []gc.Key{gc.REQ_PREV_FIELD, gc.REQ_END_LINE}
+	case gc.KEY_LEFT:
+		reqs = []gc.Key{gc.REQ_PREV_CHAR}
+	case gc.KEY_RIGHT:
+		reqs = []gc.Key{gc.REQ_NEXT_CHAR}
+	case gc.KEY_HOME:
+		reqs = []gc.Key{gc.REQ_BEG_LINE}
+	case gc.KEY_END:
+		reqs = []gc.Key{gc.REQ_END_LINE}
+	case gc.KEY_BACKSPACE, 127, 8:
+		reqs = []gc.Key{gc.REQ_DEL_PREV}
+	case gc.KEY_DC:
+		reqs = []gc.Key{gc.REQ_DEL_CHAR}
+	case 'U' - '@':
+		reqs = []gc.Key{gc.REQ_CLR_FIELD}
+	default:
+		// Bytes of multi-byte characters are passed on to be put together
+		// by the form library, which needs the ncursesw tag to do so
+		if k < ' ' || k > 0xff {
+			return false, nil
+		}
+		reqs = []gc.Key{k}
+	}
+	for _, req := range reqs {
+		err := f.form.Driver(req)
+		if ferr, ok := err.(*gc.FieldError); ok {
+			return true, f.fieldError(ferr)
+		}
+		if err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// fieldError returns the error of the field reported by the form library
+func (f *Form) fieldError(err *gc.FieldError) *FieldError {
+	for _, fd := range f.fields {
+		if fd.field == err.Field {
+			return &FieldError{Name: fd.name, Label: fd.label,
+				Err: errors.New(err.Reason)}
+		}
+	}
+	return &FieldError{Err: err}
+}
+
+// Decode validates the values entered in the form and stores them in the
+// fields of the struct pointed to by v, which must be of the type the form
+// was built from. The fields whose values are invalid are left unchanged
+// and reported by an Errors.
+func (f *Form) Decode(v interface{}) error {
+	rv, err := structValue(v)
+	if err != nil {
+		return err
+	}
+	if rv.Type() != f.typ {
+		return errors.New("Struct is not of the type of the form")
+	}
+	var errs Errors
+	var invalid *gc.FieldError
+	if f.posted {
+		// Validating the form stores the text of the current field in its
+		// buffer, or finds the first field the form library rejects
+		err := f.form.Validate()
+		if ferr, ok := err.(*gc.FieldError); ok {
+			invalid = ferr
+		} else if err != nil {
+			return err
+		}
+	}
+	for _, fd := range f.fields {
+		sv := rv.Field(fd.index)
+		value, err := fd.value(strings.TrimSpace(fd.field.Buffer()),
+			sv.Type())
+		if err == nil && invalid != nil && invalid.Field == fd.field {
+			errs = append(errs, f.fieldError(invalid))
+			continue
+		}
+		if err != nil {
+			errs = append(errs, &FieldError{Name: fd.name, Label: fd.label,
+				Err: err})
+			continue
+		}
+		sv.Set(value)
+	}
+	if errs != nil {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package form
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+// TestMain runs the tests with a screen writing to the null device, as
+// forms may only be created once curses is initialized
+func TestMain(m *testing.M) {
+	null, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	scr, err := gc.NewTerm("xterm", null, null)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	scr.End()
+	scr.Delete()
+	null.Close()
+	os.Exit(code)
+}
+
+// setText sets the text of the form field of the struct field name
+func setText(t *testing.T, f *Form, name, text string) {
+	for _, fd := range f.fields {
+		if fd.name == name {
+			if err := fd.field.SetBuffer(text); err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+	}
+	t.Fatalf("no field %s", name)
+}
+
+func TestParseTag(t *testing.T) {
+	var s struct {
+		Name   string
+		Host   string  `tui:"label=Host name,width=30,required"`
+		Port   int     `tui:"width=6,min=1,max=65535"`
+		Ratio  float32 `tui:"min=0"`
+		Count  uint8   `tui:"max=9"`
+		Proto  string  `tui:"values=tcp|udp"`
+		Addr   string  `tui:"type=ipv4"`
+		On     bool
+		Code   string `tui:"type=int"`
+		Spaced string `tui:" label=A b , required "`
+	}
+	want := []field{
+		{name: "Name", label: "Name", kind: "string", width: 20},
+		{name: "Host", label: "Host name", kind: "string", width: 30,
+			required: true},
+		{name: "Port", label: "Port", kind: "int", width: 6, min: 1,
+			max: 65535, ranged: true},
+		{name: "Ratio", label: "Ratio", kind: "float", width: 12, min: 0,
+			max: math.Inf(1), ranged: true},
+		{name: "Count", label: "Count", kind: "uint", width: 10,
+			min: math.Inf(-1), max: 9, ranged: true},
+		{name: "Proto", label: "Proto", kind: "string", width: 20,
+			values: []string{"tcp", "udp"}},
+		{name: "Addr", label: "Addr", kind: "ipv4", width: 15},
+		{name: "On", label: "On", kind: "bool", width: 5},
+		{name: "Code", label: "Code", kind: "int", width: 10},
+		{name: "Spaced", label: "A b", kind: "string", width: 20,
+			required: true},
+	}
+	rt := reflect.TypeOf(s)
+	for i, w := range want {
+		sf := rt.Field(i)
+		fd, err := parseTag(sf, sf.Tag.Get("tui"))
+		if err != nil {
+			t.Errorf("%s: %v", sf.Name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*fd, w) {
+			t.Errorf("%s: got %+v, want %+v", sf.Name, *fd, w)
+		}
+	}
+
+	var bad struct {
+		Option  string `tui:"colour=red"`
+		Type    string `tui:"type=date"`
+		Width   string `tui:"width=wide"`
+		Min     int    `tui:"min=low"`
+		Complex complex64
+		Slice   []string
+	}
+	errs := []string{
+		"Invalid tag of field Option: unknown option colour",
+		"Invalid tag of field Type: unknown type date",
+		`Invalid tag of field Width: strconv.Atoi: parsing "wide": ` +
+			"invalid syntax",
+		`Invalid tag of field Min: strconv.ParseFloat: parsing "low": ` +
+			"invalid syntax",
+		"Unsupported type of field Complex: complex64",
+		"Unsupported type of field Slice: []string",
+	}
+	rt = reflect.TypeOf(bad)
+	for i, want := range errs {
+		sf := rt.Field(i)
+		_, err := parseTag(sf, sf.Tag.Get("tui"))
+		if err == nil || err.Error() != want {
+			t.Errorf("%s: error %v, want %q", sf.Name, err, want)
+		}
+	}
+}
+
+func TestFromStruct(t *testing.T) {
+	s := struct {
+		Host    string `tui:"label=Host name,width=30"`
+		Skipped string `tui:"-"`
+		hidden  string
+		Port    int
+	}{Host: "localhost", Port: 8080}
+	f, err := FromStruct(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Free()
+	if h, w := f.Size(); h != 2 || w != len("Host name")+2+30 {
+		t.Errorf("Size is %d, %d, want 2, %d", h, w, len("Host name")+2+30)
+	}
+	for i, want := range []string{"localhost", "8080"} {
+		if got := strings.TrimSpace(f.fields[i].field.Buffer()); got != want {
+			t.Errorf("field %d holds %q, want %q", i, got, want)
+		}
+	}
+
+	if _, err := FromStruct(s); err == nil {
+		t.Error("built a form from a struct rather than a pointer")
+	}
+	if _, err := FromStruct(&struct{ hidden int }{}); err == nil {
+		t.Error("built a form without fields")
+	}
+	if _, err := FromStruct(&struct{ C chan int }{}); err == nil {
+		t.Error("built a form of an unsupported type of field")
+	}
+}
+
+type decoded struct {
+	Small  int8    `tui:"label=Small"`
+	Byte   uint8   `tui:"label=Byte"`
+	Port   int     `tui:"label=Port,min=1,max=65535"`
+	Ratio  float32 `tui:"label=Ratio,min=0"`
+	On     bool    `tui:"label=On"`
+	Name   string  `tui:"label=Name,required"`
+	Addr   string  `tui:"label=Address,type=ipv4"`
+	Proto  string  `tui:"label=Protocol,values=tcp|udp"`
+	Word   string  `tui:"label=Word,type=alpha"`
+	Code   string  `tui:"label=Code,type=alnum"`
+	Flag   string  `tui:"label=Flag,type=bool"`
+	Amount string  `tui:"label=Amount,type=float"`
+}
+
+func TestDecode(t *testing.T) {
+	var s decoded
+	f, err := FromStruct(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Free()
+
+	texts := map[string]string{
+		"Small": "-128", "Byte": "255", "Port": "22", "Ratio": "0.5",
+		"On": "true", "Name": "host", "Addr": "10.0.0.1", "Proto": "UD",
+		"Word": "hello", "Code": "abc123", "Flag": "false", "Amount": "1e3",
+	}
+	for name, text := range texts {
+		setText(t, f, name, text)
+	}
+	if err := f.Decode(&s); err != nil {
+		t.Fatal(err)
+	}
+	want := decoded{Small: -128, Byte: 255, Port: 22, Ratio: 0.5, On: true,
+		Name: "host", Addr: "10.0.0.1", Proto: "udp", Word: "hello",
+		Code: "abc123", Flag: "false", Amount: "1e3"}
+	if s != want {
+		t.Errorf("decoded %+v, want %+v", s, want)
+	}
+	// A struct of another type is left untouched, even if it begins with
+	// fields of the same names and types
+	var other struct {
+		Small int8
+		Byte  uint8
+	}
+	if err := f.Decode(&other); err == nil {
+		t.Error("decoded into a struct of another type")
+	}
+	if other.Small != 0 || other.Byte != 0 {
+		t.Errorf("struct of another type changed to %+v", other)
+	}
+
+	invalid := map[string]string{
+		"Small":  "128",
+		"Byte":   "-1",
+		"Port":   "0",
+		"Ratio":  "-1",
+		"On":     "maybe",
+		"Name":   "",
+		"Addr":   "10.0.0",
+		"Proto":  "zzz",
+		"Word":   "a1",
+		"Code":   "a-1",
+		"Flag":   "yes",
+		"Amount": "lots",
+	}
+	for name, text := range invalid {
+		setText(t, f, name, text)
+	}
+	err = f.Decode(&s)
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("Decode returned %v, want Errors", err)
+	}
+	reasons := []string{
+		"Small: is out of range",
+		"Byte: must be a positive whole number",
+		"Port: must be from 1 to 65535",
+		"Ratio: must be at least 0",
+		"On: must be true or false",
+		"Name: a value is required",
+		"Address: must be an IPv4 address",
+		"Protocol: must be one of tcp, udp",
+		"Word: must be letters only",
+		"Code: must be letters and digits only",
+		"Flag: must be true or false",
+		"Amount: must be a number",
+	}
+	if len(errs) != len(reasons) {
+		t.Fatalf("Decode reported %d errors, want %d: %v", len(errs),
+			len(reasons), errs)
+	}
+	for i, want := range reasons {
+		if errs[i].Error() != want {
+			t.Errorf("error %d is %q, want %q", i, errs[i], want)
+		}
+	}
+	// Invalid fields are left unchanged
+	if s != want {
+		t.Errorf("decoding invalid fields changed the struct to %+v", s)
+	}
+
+	if err := f.Decode(&struct{ Small int8 }{}); err == nil {
+		t.Error("decoded into a struct of another type")
+	}
+	if err := f.Decode(s); err == nil {
+		t.Error("decoded into a struct rather than a pointer")
+	}
+}
+
+func TestDecodePosted(t *testing.T) {
+	s := struct {
+		A string `tui:"type=ipv4"`
+		B string `tui:"type=ipv4"`
+		C string `tui:"values=x|y"`
+	}{"bad", "bad2", "zzz"}
+	f, err := FromStruct(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Free()
+	h, w := f.Size()
+	win, err := gc.NewWindow(h, w, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer win.Delete()
+	if err := f.Post(win); err != nil {
+		t.Fatal(err)
+	}
+	defer f.UnPost()
+
+	// The form library finds only the first invalid field, the others
+	// are found by Decode
+	err = f.Decode(&s)
+	if errs, ok := err.(Errors); !ok || len(errs) != 3 {
+		t.Errorf("Decode returned %v, want 3 errors", err)
+	}
+	if s.A != "bad" || s.B != "bad2" || s.C != "zzz" {
+		t.Errorf("invalid fields stored: %+v", s)
+	}
+}
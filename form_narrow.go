@@ -0,0 +1,18 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !ncursesw,!windows
+
+package goncurses
+
+// #include <form.h>
+import "C"
+
+// enterChar leaves all characters to the form driver, which calls the
+// character check of a custom type itself. The narrow form library only
+// takes characters of a single byte.
+func (f *Form) enterChar(k Key) (err C.int, ok bool) {
+	return C.E_OK, false
+}
@@ -0,0 +1,267 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func newFields(t *testing.T, types ...*goncurses.FieldType) []*goncurses.Field {
+	fields := make([]*goncurses.Field, len(types))
+	for i, typ := range types {
+		field, err := goncurses.NewField(1, 16, int32(i), 0, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := field.SetType(typ); err != nil {
+			t.Fatal(err)
+		}
+		fields[i] = field
+	}
+	return fields
+}
+
+func TestFormValidate(t *testing.T) {
+	fields := newFields(t, goncurses.TypeAlpha(2),
+		goncurses.TypeInteger(0, 1, 65535),
+		goncurses.TypeEnum([]string{"tcp", "udp"}, false, true),
+		goncurses.TypeIPv4())
+	for _, f := range fields {
+		defer f.Free()
+	}
+	form, err := goncurses.NewForm(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer form.Free()
+	if err := form.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer form.UnPost()
+
+	values := []string{"host", "70000", "u", "10.0.0.1"}
+	for i, v := range values {
+		fields[i].SetBuffer(v)
+	}
+	err = form.Validate()
+	ferr, ok := err.(*goncurses.FieldError)
+	if !ok {
+		t.Fatalf("Validate returned %v, want a *FieldError", err)
+	}
+	if ferr.Field != fields[1] || ferr.Index != 1 {
+		t.Errorf("Validate reported field %d, want field 1", ferr.Index)
+	}
+	if want := "must be a whole number from 1 to 65535"; ferr.Reason != want {
+		t.Errorf("Reason is %q, want %q", ferr.Reason, want)
+	}
+
+	fields[1].SetBuffer("8080")
+	if err := form.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	// A unique prefix of an enum value is completed
+	if got := strings.TrimSpace(fields[2].Buffer()); got != "udp" {
+		t.Errorf("enum field is %q, want %q", got, "udp")
+	}
+}
+
+func TestFormDriverInvalidField(t *testing.T) {
+	fields := newFields(t, goncurses.TypeAlpha(0), nil)
+	for _, f := range fields {
+		defer f.Free()
+	}
+	form, err := goncurses.NewForm(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer form.Free()
+	if err := form.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer form.UnPost()
+
+	// Digits can't be typed into the field, but may be set
+	fields[0].SetBuffer("12")
+	err = form.Driver(goncurses.REQ_NEXT_FIELD)
+	ferr, ok := err.(*goncurses.FieldError)
+	if !ok {
+		t.Fatalf("Driver returned %v, want a *FieldError", err)
+	}
+	if ferr.Field != fields[0] || ferr.Index != 0 {
+		t.Errorf("Driver reported field %d, want field 0", ferr.Index)
+	}
+	if want := "must be letters only"; ferr.Reason != want {
+		t.Errorf("Reason is %q, want %q", ferr.Reason, want)
+	}
+}
+
+func TestCustomFieldType(t *testing.T) {
+	errPort := errors.New("port must be from 1 to 65535")
+	port, err := goncurses.NewFieldType(func(s string) error {
+		if n, err := strconv.Atoi(s); err != nil || n < 1 || n > 65535 {
+			return errPort
+		}
+		return nil
+	}, nil, func(s string) string {
+		n, _ := strconv.Atoi(s)
+		return strconv.Itoa(n + 1)
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := newFields(t, nil, port)
+	form, err := goncurses.NewForm(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	fields[1].SetBuffer("0")
+	err = form.Validate()
+	if ferr, ok := err.(*goncurses.FieldError); !ok || ferr.Index != 1 ||
+		ferr.Reason != errPort.Error() {
+		t.Errorf("Validate returned %v, want the validator's error", err)
+	}
+	if err := fields[1].ValidationError(); err != errPort {
+		t.Errorf("ValidationError is %v, want %v", err, errPort)
+	}
+
+	// The invalid field was made current, so choices apply to it
+	if err := form.Driver(goncurses.REQ_NEXT_CHOICE); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(fields[1].Buffer()); got != "1" {
+		t.Errorf("next choice is %q, want %q", got, "1")
+	}
+	if err := form.Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := fields[1].ValidationError(); err != nil {
+		t.Errorf("ValidationError is %v after the field was accepted", err)
+	}
+
+	form.UnPost()
+	form.Free()
+	if err := port.Free(); err == nil {
+		t.Error("type freed while set on a field")
+	}
+	for _, f := range fields {
+		f.Free()
+	}
+	if err := port.Free(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFieldTypeCharCheck(t *testing.T) {
+	var checked []rune
+	noX, err := goncurses.NewFieldType(nil, func(r rune) bool {
+		checked = append(checked, r)
+		return r != 'x'
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer noX.Free()
+	fields := newFields(t, noX)
+	defer fields[0].Free()
+	form, err := goncurses.NewForm(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer form.Free()
+	if err := form.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer form.UnPost()
+
+	// The bytes of a multi-byte character are checked as a whole, if the
+	// form library takes them at all
+	typed := "aéx"
+	for i := 0; i < len(typed); i++ {
+		form.Driver(goncurses.Key(typed[i]))
+	}
+	if len(checked) == 0 || checked[0] != 'a' {
+		t.Fatalf("checked %q, want the characters typed", checked)
+	}
+	for _, r := range checked {
+		if !strings.ContainsRune(typed, r) {
+			t.Errorf("checked %q, which was not typed", r)
+		}
+	}
+	if err := form.Driver('x'); err == nil {
+		t.Error("rejected character entered")
+	}
+	if err := form.Driver(goncurses.REQ_VALIDATION); err != nil {
+		t.Fatal(err)
+	}
+	if got := fields[0].Buffer(); strings.ContainsRune(got, 'x') ||
+		!strings.HasPrefix(got, "a") {
+		t.Errorf("field holds %q, want the characters accepted", got)
+	}
+}
+
+func TestFormHooks(t *testing.T) {
+	fields := newFields(t, nil, goncurses.TypeInteger(0, 0, 0))
+	for _, f := range fields {
+		defer f.Free()
+	}
+	form, err := goncurses.NewForm(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer form.Free()
+
+	var log []string
+	index := func(f *goncurses.Field) string {
+		if f == fields[0] {
+			return "0"
+		}
+		return "1"
+	}
+	form.OnFieldEnter(func(f *goncurses.Field) {
+		log = append(log, "enter "+index(f))
+	})
+	form.OnFieldLeave(func(f *goncurses.Field) {
+		log = append(log, "leave "+index(f))
+	})
+	form.OnChange(func(f *goncurses.Field) {
+		log = append(log, "change "+index(f))
+	})
+	if err := form.Post(); err != nil {
+		t.Fatal(err)
+	}
+	form.Driver('a')
+	form.Driver(goncurses.REQ_NEXT_FIELD)
+	// Leaving a field unchanged, or whose text was changed back, is not a
+	// change, although the typed field is validated each time it is left
+	form.Driver(goncurses.REQ_PREV_FIELD)
+	form.Driver(goncurses.REQ_CLR_FIELD)
+	form.Driver('a')
+	form.Driver(goncurses.REQ_NEXT_FIELD)
+	form.Driver('1')
+	// Validating moves between the fields without reporting it
+	if err := form.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	form.UnPost()
+
+	want := []string{"enter 0", "change 0", "leave 0", "enter 1", "leave 1",
+		"enter 0", "leave 0", "enter 1", "change 1", "leave 1"}
+	if strings.Join(log, ", ") != strings.Join(want, ", ") {
+		t.Errorf("hooks called:\n%s\nwant:\n%s", strings.Join(log, ", "),
+			strings.Join(want, ", "))
+	}
+}
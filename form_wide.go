@@ -0,0 +1,46 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ncursesw,!windows
+
+package goncurses
+
+// #include <form.h>
+import "C"
+
+import "unicode/utf8"
+
+// enterChar enters the character k, or a byte of a multi-byte character,
+// into the current field if the field has a custom type checking each
+// character. The wide form library doesn't call the type's character check,
+// so the bytes are held until the character is complete and only passed to
+// the form driver if validateChar accepts it. The value of ok is false if
+// k is left to the form driver.
+func (f *Form) enterChar(k Key) (err C.int, ok bool) {
+	if k < ' ' || k > 0xff || k == 0x7f {
+		return C.E_OK, false
+	}
+	t := fieldTypes[C.current_field(f.form)]
+	if t == nil || t.validateChar == nil {
+		return C.E_OK, false
+	}
+	d := f.data(true)
+	d.partial = append(d.partial, byte(k))
+	if !utf8.FullRune(d.partial) {
+		return C.E_OK, true
+	}
+	r, _ := utf8.DecodeRune(d.partial)
+	b := d.partial
+	d.partial = nil
+	if !t.validateChar(r) {
+		return C.E_UNKNOWN_COMMAND, true
+	}
+	for _, c := range b {
+		if err = C.form_driver(f.form, C.int(c)); err != C.E_OK {
+			break
+		}
+	}
+	return err, true
+}
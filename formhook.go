@@ -0,0 +1,233 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+/*
+#cgo !ncursesw pkg-config: form
+#cgo ncursesw pkg-config: formw
+#include <form.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+extern void goncursesFieldInit(FORM *);
+extern void goncursesFieldTerm(FORM *);
+extern void goncursesFormInit(FORM *);
+*/
+import "C"
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// formData holds the Go functions called by a form's C hooks. The hooks are
+// dispatched by the C form to the exported functions below, which look up
+// the functions for that form.
+type formData struct {
+	form       Form
+	fieldEnter func(*Field)
+	fieldLeave func(*Field)
+	change     func(*Field)
+	pageChange func(*Form)
+	text       string // the text of the current field when it was entered
+	partial    []byte // the bytes of a character being typed, see enterChar
+
+	// The C library calls the same hook when a form is posted as when its
+	// page changes, and Validate moves between the fields, so these note
+	// what is being done
+	posting, validating bool
+}
+
+var formDataMap = make(map[*C.FORM]*formData)
+
+// data returns the form's Go state, creating it if create is true
+func (f *Form) data(create bool) *formData {
+	d := formDataMap[f.form]
+	if d == nil && create {
+		d = &formData{form: *f}
+		formDataMap[f.form] = d
+	}
+	return d
+}
+
+// OnFieldEnter sets the function called when the form is posted and each
+// time a field becomes the current field. It is passed the field. A nil
+// function removes the hook.
+func (f *Form) OnFieldEnter(fn func(*Field)) error {
+	f.data(true).fieldEnter = fn
+	return f.setHooks()
+}
+
+// OnFieldLeave sets the function called each time the current field is
+// left and when the form is unposted. It is passed the field. A nil
+// function removes the hook.
+func (f *Form) OnFieldLeave(fn func(*Field)) error {
+	f.data(true).fieldLeave = fn
+	return f.setHooks()
+}
+
+// OnPageChange sets the function called after the form changes to another
+// page. A nil function removes the hook.
+func (f *Form) OnPageChange(fn func(*Form)) error {
+	f.data(true).pageChange = fn
+	return f.setHooks()
+}
+
+// OnChange sets the function called when a field whose text the user has
+// changed is left or validated, or the form is unposted. The form library
+// notes the change, as reported by field_status, once the text is
+// validated; the function is only called if the text differs from that of
+// the field when it was entered. A nil function removes the hook.
+func (f *Form) OnChange(fn func(*Field)) error {
+	f.data(true).change = fn
+	return f.setHooks()
+}
+
+// setHooks installs or removes the form's C hooks according to the Go
+// functions set
+func (f *Form) setHooks() error {
+	d := f.data(true)
+	var fieldInit, fieldTerm, formInit C.Form_Hook
+	if d.fieldEnter != nil || d.change != nil {
+		fieldInit = C.Form_Hook(C.goncursesFieldInit)
+	}
+	if d.fieldLeave != nil || d.change != nil {
+		fieldTerm = C.Form_Hook(C.goncursesFieldTerm)
+	}
+	if d.pageChange != nil {
+		formInit = C.Form_Hook(C.goncursesFormInit)
+	}
+	if err := C.set_field_init(f.form, fieldInit); err != C.E_OK {
+		return ncursesError(syscall.Errno(err))
+	}
+	if err := C.set_field_term(f.form, fieldTerm); err != C.E_OK {
+		return ncursesError(syscall.Errno(err))
+	}
+	err := C.set_form_init(f.form, formInit)
+	return ncursesError(syscall.Errno(err))
+}
+
+// validated reports the move, if any, from the field cur on page to the
+// field left current by Validate, which moves between the fields with the
+// hooks suppressed
+func (d *formData) validated(cur *C.FIELD, page C.int) {
+	d.validating = false
+	form := d.form.form
+	if C.current_field(form) == cur {
+		return
+	}
+	if d.fieldLeave != nil {
+		d.fieldLeave((*Field)(cur))
+	}
+	if d.pageChange != nil && C.form_page(form) != page {
+		d.pageChange(&d.form)
+	}
+	if d.fieldEnter != nil {
+		d.fieldEnter((*Field)(C.current_field(form)))
+	}
+}
+
+//export goncursesFieldInit
+func goncursesFieldInit(form *C.FORM) {
+	d := formDataMap[form]
+	if d == nil {
+		return
+	}
+	field := C.current_field(form)
+	d.text = fieldText(field)
+	if d.fieldEnter != nil && !d.validating {
+		d.fieldEnter((*Field)(field))
+	}
+}
+
+//export goncursesFieldTerm
+func goncursesFieldTerm(form *C.FORM) {
+	d := formDataMap[form]
+	if d == nil {
+		return
+	}
+	field := C.current_field(form)
+	if d.change != nil && bool(C.field_status(field)) {
+		if text := fieldText(field); text != d.text {
+			d.text = text
+			d.change((*Field)(field))
+		}
+	}
+	if d.fieldLeave != nil && !d.validating {
+		d.fieldLeave((*Field)(field))
+	}
+}
+
+//export goncursesFormInit
+func goncursesFormInit(form *C.FORM) {
+	d := formDataMap[form]
+	if d != nil && d.pageChange != nil && !d.posting && !d.validating {
+		d.pageChange(&d.form)
+	}
+}
+
+// The functions of custom field types are called by the form library with
+// the handle of the Go FieldType, passed to them as the argument of the C
+// type by fieldtype.go.
+
+// customType returns the Go type of the handle h
+func customType(h C.uintptr_t) *FieldType {
+	return handleValue(uintptr(h)).(*FieldType)
+}
+
+// fieldText returns the text of the field without leading and trailing
+// blanks
+func fieldText(field *C.FIELD) string {
+	return strings.TrimSpace(C.GoString(C.field_buffer(field, 0)))
+}
+
+//export goncursesFieldCheck
+func goncursesFieldCheck(field *C.FIELD, h C.uintptr_t) C.bool {
+	err := customType(h).validateField(fieldText(field))
+	if err != nil {
+		fieldErrors[field] = err
+	} else {
+		delete(fieldErrors, field)
+	}
+	return C.bool(err == nil)
+}
+
+// goncursesCharCheck is passed a character of a single byte, as the wide
+// form library doesn't check the characters typed. See enterChar.
+//
+//export goncursesCharCheck
+func goncursesCharCheck(c C.int, h C.uintptr_t) C.bool {
+	return C.bool(customType(h).validateChar(rune(c)))
+}
+
+//export goncursesFieldNext
+func goncursesFieldNext(field *C.FIELD, h C.uintptr_t) C.bool {
+	return fieldChoice(field, customType(h).next)
+}
+
+//export goncursesFieldPrev
+func goncursesFieldPrev(field *C.FIELD, h C.uintptr_t) C.bool {
+	return fieldChoice(field, customType(h).prev)
+}
+
+// fieldChoice replaces the text of the field with the choice made by fn,
+// returning false if there is no other choice
+func fieldChoice(field *C.FIELD, fn func(string) string) C.bool {
+	if fn == nil {
+		return false
+	}
+	text := fieldText(field)
+	choice := fn(text)
+	if choice == text {
+		return false
+	}
+	cstr := C.CString(choice)
+	defer C.free(unsafe.Pointer(cstr))
+	return C.set_field_buffer(field, 0, cstr) == C.E_OK
+}
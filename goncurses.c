@@ -3,8 +3,12 @@
 // license that can be found in the LICENSE file.
 
 #include <stdbool.h>
+#include <stdio.h>
 #include <stdlib.h>
 #include <curses.h>
+#ifndef PDCURSES
+#include <term.h>
+#endif
 
 #ifdef PDCURSES
 bool is_term_resized(int y, int x) { return is_termresized(); }
@@ -56,6 +60,14 @@ bool ncurses_is_keypad(const WINDOW *win) {
 #endif
 }
 
+bool ncurses_is_scrollok(const WINDOW *win) {
+#ifdef PDCURSES
+	return win->_scroll;
+#else
+	return is_scrollok(win);
+#endif
+}
+
 bool ncurses_is_pad(const WINDOW *win) {
 #ifdef PDCURSES
 	return false; /* no known built-in way to test for this */
@@ -73,6 +85,16 @@ bool ncurses_is_subwin(const WINDOW *win) {
 }
 
 
+int ncurses_wgetscrreg(WINDOW *win, int *top, int *bot) {
+#ifdef PDCURSES
+	*top = win->_tmarg;
+	*bot = win->_bmarg;
+	return OK;
+#else
+	return wgetscrreg(win, top, bot);
+#endif
+}
+
 bool ncurses_has_mouse(void) {
 #if NCURSES_VERSION_MINOR < 8
 	return false;
@@ -81,6 +103,33 @@ bool ncurses_has_mouse(void) {
 #endif
 }
 
+/* Returns the sequence which begins a mouse event report or NULL if the
+ * terminal doesn't describe one */
+const char *ncurses_key_mouse(void) {
+#ifdef PDCURSES
+	return NULL;
+#else
+	char *kmous = tigetstr("kmous");
+	if (kmous == (char *) -1)
+		return NULL;
+	return kmous;
+#endif
+}
+
+/* Sends an xterm private mode sequence and adds or removes a key definition
+ * for the start of mouse reports using its SGR encoding */
+int ncurses_xterm_mouse_mode(const char *mode, int sgrkey) {
+#ifdef PDCURSES
+	return ERR;
+#else
+	if (sgrkey >= 0 && define_key("\033[<", sgrkey) == ERR)
+		return ERR;
+	if (putp(mode) == ERR) /* written to stdout using putchar */
+		return ERR;
+	return fflush(stdout) == 0 ? OK : ERR;
+#endif
+}
+
 int ncurses_touchwin(WINDOW *win) { return touchwin(win); }
 int ncurses_untouchwin(WINDOW *win) { return untouchwin(win); }
 int ncurses_wattrset(WINDOW *win, int attr) { return wattrset(win, attr); }
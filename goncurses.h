@@ -20,15 +20,19 @@ bool ncurses_has_mouse(void);
 bool ncurses_is_cleared(const WINDOW *win);
 bool ncurses_is_keypad(const WINDOW *win);
 bool ncurses_is_pad(const WINDOW *win);
+bool ncurses_is_scrollok(const WINDOW *win);
 bool ncurses_is_subwin(const WINDOW *win);
+const char *ncurses_key_mouse(void);
 int ncurses_touchwin(WINDOW *win);
 int ncurses_ungetch(int ch);
 int ncurses_untouchwin(WINDOW *win);
 int ncurses_wattroff(WINDOW *, int);
 int ncurses_wattron(WINDOW *, int);
 int ncurses_wattrset(WINDOW *win, int attr);
+int ncurses_wgetscrreg(WINDOW *win, int *top, int *bot);
 WINDOW * ncurses_wgetparent(const WINDOW *win);
 int ncurses_wstandend(WINDOW *win);
 int ncurses_wstandout(WINDOW *win);
+int ncurses_xterm_mouse_mode(const char *mode, int sgrkey);
 
 #endif /* _GONCURSES_ */
@@ -0,0 +1,75 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import (
+	"os"
+	"strings"
+)
+
+// History is a bounded list of previously entered lines, oldest first, for
+// use with a LineEditor. It is held in memory but may be loaded from and
+// saved to a file, one line per line of the file, to persist it between
+// runs of a program.
+type History struct {
+	lines []string
+	max   int
+}
+
+// NewHistory returns an empty History which holds at most size lines. Once
+// full, the oldest line is discarded as each new line is added.
+func NewHistory(size int) *History {
+	return &History{max: size}
+}
+
+// Add appends line to the history. Empty lines and lines identical to the
+// most recent line are ignored.
+func (h *History) Add(line string) {
+	if line == "" || h.max <= 0 ||
+		len(h.lines) > 0 && h.lines[len(h.lines)-1] == line {
+		return
+	}
+	h.lines = append(h.lines, line)
+	if len(h.lines) > h.max {
+		h.lines = h.lines[len(h.lines)-h.max:]
+	}
+}
+
+// At returns the line at index i, where zero is the oldest line
+func (h *History) At(i int) string {
+	return h.lines[i]
+}
+
+// Len returns the number of lines in the history
+func (h *History) Len() int {
+	return len(h.lines)
+}
+
+// Load adds the lines stored in the file at path to the history. It is not
+// an error for the file not to exist.
+func (h *History) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		h.Add(line)
+	}
+	return nil
+}
+
+// Save writes the history to the file at path, replacing its contents. The
+// file is created, readable only by the current user, if necessary.
+func (h *History) Save(path string) error {
+	var data string
+	if len(h.lines) > 0 {
+		data = strings.Join(h.lines, "\n") + "\n"
+	}
+	return os.WriteFile(path, []byte(data), 0600)
+}
@@ -0,0 +1,714 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+// #include <curses.h>
+import "C"
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInterrupted is returned by LineEditor.ReadLine when the user presses
+// Ctrl-C. Ctrl-C only reaches the editor when Raw mode is enabled;
+// otherwise it raises an interrupt signal as usual.
+var ErrInterrupted = errors.New("Interrupted")
+
+// editorPoll is how often, in milliseconds, a LineEditor waiting for input
+// checks whether its context has been cancelled
+const editorPoll = 50
+
+// maxPopupItems limits the height of the completion popup
+const maxPopupItems = 8
+
+// Completer provides candidates for completing the text being entered in a
+// LineEditor. Complete is passed the line and the cursor position within it,
+// counted in runes. It returns the candidates which may replace the text
+// between start and the cursor, typically the word being typed.
+type Completer interface {
+	Complete(line string, pos int) (start int, candidates []string)
+}
+
+// CompleterFunc adapts an ordinary function to the Completer interface
+type CompleterFunc func(line string, pos int) (int, []string)
+
+// Complete calls f(line, pos)
+func (f CompleterFunc) Complete(line string, pos int) (int, []string) {
+	return f(line, pos)
+}
+
+type EditMode byte
+
+// Key binding modes of a LineEditor
+const (
+	EDIT_EMACS EditMode = iota // emacs style bindings, as in bash
+	EDIT_VI                    // vi style bindings with insert and normal modes
+)
+
+// LineEditor reads a single line of input from the user on one line of a
+// window, with cursor movement and editing within the text, horizontal
+// scrolling of text longer than the field, history and completion. It is
+// a replacement for GetString.
+//
+// In both modes the arrow, home, end, delete and backspace keys behave as
+// expected, Tab requests completion, the up and down keys recall lines
+// from the history and Enter accepts the line. Ctrl-D on an empty line
+// returns io.EOF.
+//
+// Emacs mode additionally supports Ctrl-A, Ctrl-E, Ctrl-B and Ctrl-F for
+// movement, Alt-B and Alt-F to move by word, Ctrl-K, Ctrl-U, Ctrl-W and
+// Alt-D to delete text, Ctrl-Y to paste the last deleted text, Ctrl-T to
+// transpose characters and Ctrl-P and Ctrl-N for history.
+//
+// Vi mode starts in insert mode. Escape switches to normal mode, where the
+// usual vi commands are available: h, l, 0, ^, $, w, b and e for movement;
+// i, a, I and A to return to insert mode; x, X, D, C, S, r, the d and c
+// operators combined with a movement (or repeated to act on the whole line)
+// and p and P to edit; and k and j for history.
+type LineEditor struct {
+	Prompt    string    // printed before the text being edited
+	Mask      rune      // if non-zero, displayed in place of each character
+	Mode      EditMode  // key bindings to use
+	History   *History  // lines are recalled from and added to History
+	Completer Completer // provides completion when Tab is pressed
+
+	win          *Window
+	y, x, width  int
+	buf          []rune
+	pos          int
+	scroll       int
+	hist         int
+	edit         []rune
+	kill         []rune
+	meta, normal bool
+	op           rune
+}
+
+// NewLineEditor returns a LineEditor which reads input on the window w in a
+// field width columns wide starting at y, x. The prompt, if any, is
+// included in the width of the field.
+func NewLineEditor(w *Window, y, x, width int) *LineEditor {
+	return &LineEditor{win: w, y: y, x: x, width: width}
+}
+
+// ReadLine lets the user enter a line of text and returns it once Enter is
+// pressed. It returns early with ctx.Err() if the context is cancelled,
+// io.EOF if Ctrl-D is pressed on an empty line or ErrInterrupted if Ctrl-C
+// is pressed. Lines entered while Mask is not set are added to the
+// History, if any.
+//
+// ReadLine turns on the window's Keypad and sets its input Timeout in
+// order to poll the context. The timeout is restored to blocking mode
+// before returning. Echo should be turned off.
+func (e *LineEditor) ReadLine(ctx context.Context) (string, error) {
+	e.buf, e.pos, e.scroll, e.kill = nil, 0, 0, nil
+	e.meta, e.normal, e.op = false, false, 0
+	if e.History != nil {
+		e.hist = e.History.Len()
+	}
+	e.win.Keypad(true)
+	e.win.Timeout(editorPoll)
+	defer e.win.Timeout(-1)
+
+	for {
+		e.draw()
+		k, r, err := e.readKey(ctx, e.win)
+		if err != nil {
+			return "", err
+		}
+		var done bool
+		switch {
+		case e.meta:
+			e.meta = false
+			e.handleMeta(k, r)
+		case e.normal:
+			done, err = e.handleNormal(k, r)
+		default:
+			done, err = e.handleInsert(ctx, k, r)
+		}
+		if err != nil {
+			return "", err
+		}
+		if done {
+			line := string(e.buf)
+			if e.History != nil && e.Mask == 0 {
+				e.History.Add(line)
+			}
+			return line, nil
+		}
+	}
+}
+
+// readKey waits for a key press on the window w. Printable characters are
+// returned as a rune, decoding multi-byte UTF-8 input; other keys are
+// returned as a Key with a zero rune.
+func (e *LineEditor) readKey(ctx context.Context, w *Window) (Key, rune,
+	error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, 0, ctx.Err()
+		default:
+		}
+		k := w.GetChar()
+		switch {
+		case k == 0:
+			continue
+		case k >= 0x80 && k < 0x100:
+			seq := []byte{byte(k)}
+			for !utf8.FullRune(seq) {
+				k = w.GetChar()
+				if k == 0 || k >= 0x100 {
+					break
+				}
+				seq = append(seq, byte(k))
+			}
+			r, _ := utf8.DecodeRune(seq)
+			return 0, r, nil
+		case k >= 0x20 && k < 0x7f:
+			return k, rune(k), nil
+		}
+		return k, 0, nil
+	}
+}
+
+// ctrl returns the key produced by holding control and pressing c
+func ctrl(c byte) Key {
+	return Key(c & 0x1f)
+}
+
+func isAccept(k Key) bool {
+	return k == KEY_RETURN || k == KEY_ENTER || k == '\r'
+}
+
+func isBackspace(k Key) bool {
+	return k == KEY_BACKSPACE || k == ctrl('H') || k == 0x7f
+}
+
+// handleInsert handles keys in emacs mode and vi's insert mode
+func (e *LineEditor) handleInsert(ctx context.Context, k Key, r rune) (bool,
+	error) {
+	if r != 0 {
+		e.insert([]rune{r})
+		return false, nil
+	}
+	switch {
+	case isAccept(k):
+		return true, nil
+	case k == ctrl('C'):
+		return false, ErrInterrupted
+	case k == ctrl('D') && len(e.buf) == 0:
+		return false, io.EOF
+	case k == KEY_TAB:
+		return false, e.complete(ctx)
+	case k == KEY_LEFT:
+		e.move(e.pos - 1)
+	case k == KEY_RIGHT:
+		e.move(e.pos + 1)
+	case k == KEY_HOME:
+		e.move(0)
+	case k == KEY_END:
+		e.move(len(e.buf))
+	case k == KEY_UP:
+		e.history(-1)
+	case k == KEY_DOWN:
+		e.history(1)
+	case isBackspace(k):
+		e.delete(e.pos-1, e.pos, false)
+	case k == KEY_DC, k == ctrl('D'):
+		e.delete(e.pos, e.pos+1, false)
+	case k == ctrl('W'):
+		e.delete(wordStart(e.buf, e.pos), e.pos, true)
+	case k == ctrl('U'):
+		e.delete(0, e.pos, true)
+	case k == 27:
+		if e.Mode == EDIT_VI {
+			e.normal = true
+			e.move(e.pos - 1)
+		} else {
+			e.meta = true
+		}
+	case e.Mode == EDIT_VI:
+		Beep()
+	case k == ctrl('A'):
+		e.move(0)
+	case k == ctrl('E'):
+		e.move(len(e.buf))
+	case k == ctrl('B'):
+		e.move(e.pos - 1)
+	case k == ctrl('F'):
+		e.move(e.pos + 1)
+	case k == ctrl('P'):
+		e.history(-1)
+	case k == ctrl('N'):
+		e.history(1)
+	case k == ctrl('K'):
+		e.delete(e.pos, len(e.buf), true)
+	case k == ctrl('Y'):
+		e.insert(e.kill)
+	case k == ctrl('T'):
+		if e.pos > 0 && len(e.buf) > 1 {
+			if e.pos == len(e.buf) {
+				e.pos--
+			}
+			e.buf[e.pos-1], e.buf[e.pos] = e.buf[e.pos], e.buf[e.pos-1]
+			e.pos++
+		}
+	default:
+		Beep()
+	}
+	return false, nil
+}
+
+// handleMeta handles the key following Escape, or Alt, in emacs mode
+func (e *LineEditor) handleMeta(k Key, r rune) {
+	switch {
+	case r == 'b', r == 'B':
+		e.move(wordStart(e.buf, e.pos))
+	case r == 'f', r == 'F':
+		e.move(wordEnd(e.buf, e.pos))
+	case r == 'd', r == 'D':
+		e.delete(e.pos, wordEnd(e.buf, e.pos), true)
+	case isBackspace(k):
+		e.delete(wordStart(e.buf, e.pos), e.pos, true)
+	default:
+		Beep()
+	}
+}
+
+// handleNormal handles keys in vi's normal mode
+func (e *LineEditor) handleNormal(k Key, r rune) (bool, error) {
+	if op := e.op; op != 0 {
+		e.op = 0
+		if op == 'r' {
+			if r == 0 || e.pos >= len(e.buf) {
+				Beep()
+				return false, nil
+			}
+			e.buf[e.pos] = r
+			return false, nil
+		}
+		start, end := e.pos, e.pos
+		if r == op {
+			start, end = 0, len(e.buf)
+		} else if to, ok := e.motion(k, r); ok {
+			if to < e.pos {
+				start = to
+			} else {
+				end = to
+				if r == 'e' {
+					end++
+				}
+			}
+		} else {
+			Beep()
+			return false, nil
+		}
+		e.delete(start, end, true)
+		e.normal = op == 'd'
+		e.clampNormal()
+		return false, nil
+	}
+
+	if to, ok := e.motion(k, r); ok {
+		e.move(to)
+		e.clampNormal()
+		return false, nil
+	}
+	switch {
+	case isAccept(k):
+		return true, nil
+	case k == ctrl('C'):
+		return false, ErrInterrupted
+	case k == ctrl('D') && len(e.buf) == 0:
+		return false, io.EOF
+	case r == 'i':
+		e.normal = false
+	case r == 'a':
+		e.normal = false
+		e.move(e.pos + 1)
+	case r == 'I':
+		e.normal = false
+		e.move(firstNonBlank(e.buf))
+	case r == 'A':
+		e.normal = false
+		e.move(len(e.buf))
+	case r == 'x', k == KEY_DC:
+		e.delete(e.pos, e.pos+1, true)
+	case r == 'X':
+		e.delete(e.pos-1, e.pos, true)
+	case r == 'D':
+		e.delete(e.pos, len(e.buf), true)
+	case r == 'C':
+		e.delete(e.pos, len(e.buf), true)
+		e.normal = false
+	case r == 'S':
+		e.delete(0, len(e.buf), true)
+		e.normal = false
+	case r == 'd', r == 'c', r == 'r':
+		e.op = r
+	case r == 'p':
+		e.move(e.pos + 1)
+		e.insert(e.kill)
+		e.move(e.pos - 1)
+	case r == 'P':
+		e.insert(e.kill)
+		e.move(e.pos - 1)
+	case r == 'k', r == '-', k == KEY_UP:
+		e.history(-1)
+	case r == 'j', r == '+', k == KEY_DOWN:
+		e.history(1)
+	default:
+		Beep()
+	}
+	e.clampNormal()
+	return false, nil
+}
+
+// motion returns the cursor position a vi movement command would move to
+func (e *LineEditor) motion(k Key, r rune) (int, bool) {
+	switch {
+	case r == 'h', k == KEY_LEFT, isBackspace(k):
+		return e.pos - 1, true
+	case r == 'l', r == ' ', k == KEY_RIGHT:
+		return e.pos + 1, true
+	case r == '0', k == KEY_HOME:
+		return 0, true
+	case r == '^':
+		return firstNonBlank(e.buf), true
+	case r == '$', k == KEY_END:
+		return len(e.buf), true
+	case r == 'w':
+		i := e.pos
+		for i < len(e.buf) && !unicode.IsSpace(e.buf[i]) {
+			i++
+		}
+		for i < len(e.buf) && unicode.IsSpace(e.buf[i]) {
+			i++
+		}
+		return i, true
+	case r == 'b':
+		return wordStart(e.buf, e.pos), true
+	case r == 'e':
+		return wordEnd(e.buf, e.pos+1) - 1, true
+	}
+	return 0, false
+}
+
+// clampNormal keeps the cursor on a character while in vi's normal mode
+func (e *LineEditor) clampNormal() {
+	if e.normal && e.pos >= len(e.buf) {
+		e.move(len(e.buf) - 1)
+	}
+}
+
+// move places the cursor at pos, limited to the bounds of the line
+func (e *LineEditor) move(pos int) {
+	if pos > len(e.buf) {
+		pos = len(e.buf)
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	e.pos = pos
+}
+
+// insert inserts text at the cursor, leaving the cursor after it
+func (e *LineEditor) insert(text []rune) {
+	buf := make([]rune, 0, len(e.buf)+len(text))
+	buf = append(append(append(buf, e.buf[:e.pos]...), text...),
+		e.buf[e.pos:]...)
+	e.buf = buf
+	e.pos += len(text)
+}
+
+// delete removes the text from start to end, leaving the cursor at start.
+// If kill is true the text is saved for pasting with Ctrl-Y, or p in vi.
+func (e *LineEditor) delete(start, end int, kill bool) {
+	if start < 0 {
+		start = 0
+	}
+	if end > len(e.buf) {
+		end = len(e.buf)
+	}
+	if start >= end {
+		return
+	}
+	if kill {
+		e.kill = append([]rune(nil), e.buf[start:end]...)
+	}
+	e.buf = append(e.buf[:start], e.buf[end:]...)
+	e.pos = start
+}
+
+// history replaces the line with the previous (dir < 0) or next (dir > 0)
+// line in the history. The line being edited is kept while browsing.
+func (e *LineEditor) history(dir int) {
+	if e.History == nil || e.Mask != 0 {
+		Beep()
+		return
+	}
+	i := e.hist + dir
+	if i < 0 || i > e.History.Len() {
+		Beep()
+		return
+	}
+	if e.hist == e.History.Len() {
+		e.edit = append([]rune(nil), e.buf...)
+	}
+	e.hist = i
+	if i == e.History.Len() {
+		e.buf = e.edit
+	} else {
+		e.buf = []rune(e.History.At(i))
+	}
+	e.pos = len(e.buf)
+	e.clampNormal()
+}
+
+func wordStart(buf []rune, pos int) int {
+	for pos > 0 && unicode.IsSpace(buf[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(buf[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+func wordEnd(buf []rune, pos int) int {
+	for pos < len(buf) && unicode.IsSpace(buf[pos]) {
+		pos++
+	}
+	for pos < len(buf) && !unicode.IsSpace(buf[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func firstNonBlank(buf []rune) int {
+	for i, r := range buf {
+		if !unicode.IsSpace(r) {
+			return i
+		}
+	}
+	return len(buf)
+}
+
+// runesWidth returns the width of text as displayed by the editor
+func (e *LineEditor) runesWidth(text []rune) (width int) {
+	for _, r := range text {
+		width += e.runeWidth(r)
+	}
+	return
+}
+
+func (e *LineEditor) runeWidth(r rune) int {
+	if e.Mask != 0 {
+		r = e.Mask
+	}
+	return RuneWidth(r)
+}
+
+// draw displays the prompt and the visible portion of the line, scrolling
+// horizontally to keep the cursor within the field
+func (e *LineEditor) draw() {
+	pw := StringWidth(e.Prompt)
+	avail := e.width - pw
+	if avail < 1 {
+		avail = 1
+	}
+	if e.pos < e.scroll {
+		e.scroll = e.pos
+	}
+	for e.scroll < e.pos && e.runesWidth(e.buf[e.scroll:e.pos]) >= avail {
+		e.scroll++
+	}
+
+	var text strings.Builder
+	col := 0
+	for _, r := range e.buf[e.scroll:] {
+		if e.Mask != 0 {
+			r = e.Mask
+		}
+		if col+RuneWidth(r) > avail {
+			break
+		}
+		text.WriteRune(r)
+		col += RuneWidth(r)
+	}
+	text.WriteString(strings.Repeat(" ", avail-col))
+
+	e.win.MovePrint(e.y, e.x, Truncate(e.Prompt, e.width, ""))
+	e.win.Print(text.String())
+	e.win.Move(e.y, e.x+pw+e.runesWidth(e.buf[e.scroll:e.pos]))
+	e.win.Refresh()
+}
+
+// complete asks the Completer for candidates and either completes the
+// word, extends it to the candidates' common prefix or, failing that,
+// offers the candidates in a popup list.
+func (e *LineEditor) complete(ctx context.Context) error {
+	if e.Completer == nil || e.Mask != 0 {
+		Beep()
+		return nil
+	}
+	start, cands := e.Completer.Complete(string(e.buf), e.pos)
+	if start < 0 || start > e.pos {
+		start = e.pos
+	}
+	if len(cands) == 0 {
+		Beep()
+		return nil
+	}
+	choice := cands[0]
+	if len(cands) > 1 {
+		choice = commonPrefix(cands)
+		if utf8.RuneCountInString(choice) <= e.pos-start {
+			var err error
+			if choice, err = e.popup(ctx, cands); err != nil ||
+				choice == "" {
+				return err
+			}
+		}
+	}
+	e.delete(start, e.pos, false)
+	e.insert([]rune(choice))
+	return nil
+}
+
+func commonPrefix(strs []string) string {
+	prefix := []rune(strs[0])
+	for _, s := range strs[1:] {
+		i := 0
+		for _, r := range s {
+			if i >= len(prefix) || prefix[i] != r {
+				break
+			}
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	return string(prefix)
+}
+
+// popup displays items in a list below the cursor, or above it if there is
+// no room below, and lets the user choose one. It returns the chosen item
+// or an empty string if the list was dismissed. What the popup covered on
+// the screen is restored before returning.
+func (e *LineEditor) popup(ctx context.Context, items []string) (string,
+	error) {
+	wy, wx := e.win.YX()
+	cy, cx := e.win.CursorYX()
+	rows, cols := StdScr().MaxYX()
+
+	h := len(items)
+	if h > maxPopupItems {
+		h = maxPopupItems
+	}
+	if h+2 > rows {
+		h = rows - 2
+	}
+	w := 0
+	for _, item := range items {
+		if sw := StringWidth(item); sw > w {
+			w = sw
+		}
+	}
+	if w+2 > cols {
+		w = cols - 2
+	}
+	if h < 1 || w < 1 {
+		return "", nil
+	}
+	y, x := wy+cy+1, wx+cx
+	if y+h+2 > rows {
+		y = wy + cy - h - 2
+	}
+	if y < 0 {
+		y = 0
+	}
+	if x+w+2 > cols {
+		x = cols - w - 2
+	}
+
+	// Keep a copy of the physical screen under the popup to restore it
+	save, err := NewWindow(h+2, w+2, y, x)
+	if err != nil {
+		return "", nil
+	}
+	defer save.Delete()
+	C.copywin(C.curscr, save.win, C.int(y), C.int(x), 0, 0, C.int(h+1),
+		C.int(w+1), 0)
+	defer func() {
+		save.Touch()
+		save.Refresh()
+	}()
+
+	pop, err := NewWindow(h+2, w+2, y, x)
+	if err != nil {
+		return "", nil
+	}
+	defer pop.Delete()
+	pop.Keypad(true)
+	pop.Timeout(editorPoll)
+
+	sel, top := 0, 0
+	for {
+		if sel < top {
+			top = sel
+		}
+		if sel >= top+h {
+			top = sel - h + 1
+		}
+		pop.Erase()
+		pop.Box(0, 0)
+		for i := 0; i < h && top+i < len(items); i++ {
+			text := Truncate(items[top+i], w, "…")
+			text += strings.Repeat(" ", w-StringWidth(text))
+			if top+i == sel {
+				pop.AttrOn(A_REVERSE)
+			}
+			pop.MovePrint(i+1, 1, text)
+			pop.AttrOff(A_REVERSE)
+		}
+		pop.Refresh()
+
+		k, r, err := e.readKey(ctx, pop)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case k == KEY_TAB, k == KEY_DOWN, k == ctrl('N'):
+			sel = (sel + 1) % len(items)
+		case k == KEY_BTAB, k == KEY_UP, k == ctrl('P'):
+			sel = (sel + len(items) - 1) % len(items)
+		case k == KEY_PAGEDOWN:
+			if sel += h; sel >= len(items) {
+				sel = len(items) - 1
+			}
+		case k == KEY_PAGEUP:
+			if sel -= h; sel < 0 {
+				sel = 0
+			}
+		case isAccept(k):
+			return items[sel], nil
+		case k == 27, k == ctrl('G'), k == ctrl('C'):
+			return "", nil
+		default:
+			// Dismiss the list and let the editor handle the key
+			if r < utf8.RuneSelf {
+				UnGetChar(Char(k))
+			}
+			return "", nil
+		}
+	}
+}
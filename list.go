@@ -0,0 +1,642 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+// #include <menu.h>
+import "C"
+
+import (
+	"strings"
+	"syscall"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Style is the appearance of an item of a List
+type Style struct {
+	Attr Char  // attributes OR'd together, like A_BOLD|A_UNDERLINE
+	Pair int16 // color pair, or zero for the window's colors
+}
+
+// ListItem is an entry of a List. An item is a header, a separator or an
+// ordinary item which may be made current, selected and activated.
+type ListItem struct {
+	// Text is shown in the item's row. Each line of a multi-line text is
+	// shown in a row of its own. Patterns are matched against the first.
+	Text string
+
+	// Prefix is shown in a column before the text, such as an icon. The
+	// column is as wide as the widest prefix of the list.
+	Prefix string
+
+	Style    Style
+	Value    bool // selected, in a multi-select list
+	Disabled bool // shown dimmed and may not be made current
+
+	// Header makes the item the heading of a group of items. It is shown
+	// in bold across the list and may not be made current.
+	Header bool
+
+	// Separator makes the item a line between groups of items. Its other
+	// fields are ignored.
+	Separator bool
+
+	UserData interface{}
+}
+
+// selectable returns true if the item may be made current
+func (it *ListItem) selectable() bool {
+	return !it.Header && !it.Separator && !it.Disabled
+}
+
+// lines returns the rows of text of the item
+func (it *ListItem) lines() []string {
+	if it.Separator {
+		return []string{""}
+	}
+	return strings.Split(it.Text, "\n")
+}
+
+// List is a scrolling list of items drawn by Go rather than the C menu
+// library, so that each item may have its own style, span several lines and
+// contain any UTF-8 text. Items may be grouped under headers and between
+// separators, neither of which can be made current.
+//
+// A List handles keys and mouse events as a Menu does, using the same
+// keymap and requests, so that one may be swapped for the other. The list
+// is drawn to its window whenever it changes; the window must be refreshed
+// for the changes to appear.
+type List struct {
+	Mark               string // shown before the current item, "-" by default
+	Checked, Unchecked string // marks of a multi-select list, "[x]" and "[ ]"
+	Fore               Char   // added to the current item, A_REVERSE by default
+	Grey               Char   // added to disabled items, A_DIM by default
+
+	win        *Window
+	items      []*ListItem
+	current    int // index of the current item, or -1 if there is none
+	top        int // the first row shown
+	multi      bool
+	pattern    []rune
+	partial    []byte // bytes of a multi-byte character being typed
+	keys       map[Key]int
+	activate   func(*ListItem)
+	itemChange func(*ListItem)
+}
+
+// NewList returns a List of the items drawn in the window win. The first
+// item which may be made current is current.
+func NewList(items []*ListItem, win *Window) *List {
+	l := &List{Mark: "-", Checked: "[x]", Unchecked: "[ ]", Fore: A_REVERSE,
+		Grey: A_DIM, win: win, current: -1}
+	l.items = append(l.items, items...)
+	l.current = l.next(-1, 1)
+	l.Redraw()
+	return l
+}
+
+// Window returns the window the list is drawn to
+func (l *List) Window() *Window {
+	return l.win
+}
+
+// Count returns the number of items in the list
+func (l *List) Count() int {
+	return len(l.items)
+}
+
+// Items returns the items of the list
+func (l *List) Items() []*ListItem {
+	return append([]*ListItem(nil), l.items...)
+}
+
+// SetItems replaces the items of the list. The current item stays current
+// if it is among the new items, otherwise the first item which may be made
+// current is.
+func (l *List) SetItems(items []*ListItem) {
+	cur := l.Current()
+	l.items = append(l.items[:0:0], items...)
+	l.current, l.top, l.pattern = l.next(-1, 1), 0, nil
+	for i, item := range l.items {
+		if item == cur && item.selectable() {
+			l.current = i
+		}
+	}
+	l.show()
+	if l.Current() != cur && l.itemChange != nil {
+		l.itemChange(l.Current())
+	}
+	l.Redraw()
+}
+
+// Current returns the current item or nil if there is none
+func (l *List) Current() *ListItem {
+	if l.current < 0 {
+		return nil
+	}
+	return l.items[l.current]
+}
+
+// SetCurrent makes the item current, scrolling the list to show it
+func (l *List) SetCurrent(item *ListItem) error {
+	for i, it := range l.items {
+		if it != item {
+			continue
+		}
+		if !it.selectable() {
+			return listError(C.E_NOT_SELECTABLE)
+		}
+		l.pattern = nil
+		l.setCurrent(i)
+		return nil
+	}
+	return listError(C.E_BAD_ARGUMENT)
+}
+
+// OnActivate sets the function called by HandleKey when an item is
+// activated by the REQ_ACTIVATE request or double clicked. It is passed the
+// current item. A nil function removes the hook.
+func (l *List) OnActivate(fn func(*ListItem)) {
+	l.activate = fn
+}
+
+// OnItemChange sets the function called each time the current item
+// changes. It is passed the new current item. A nil function removes the
+// hook.
+func (l *List) OnItemChange(fn func(*ListItem)) {
+	l.itemChange = fn
+}
+
+// SetKeyMap gives the list its own keymap in place of MenuKeys. A nil map
+// restores the use of MenuKeys.
+func (l *List) SetKeyMap(keys map[Key]int) {
+	l.keys = keys
+}
+
+// SetMultiSelect allows more than one item to be selected. The items
+// selected are toggled by REQ_TOGGLE, which space is mapped to, and are
+// shown with check marks.
+func (l *List) SetMultiSelect(on bool) {
+	l.multi = on
+	l.Redraw()
+}
+
+// Selected returns the selected items of a multi-select list, or the
+// current item of any other list
+func (l *List) Selected() []*ListItem {
+	if !l.multi {
+		if cur := l.Current(); cur != nil {
+			return []*ListItem{cur}
+		}
+		return nil
+	}
+	var items []*ListItem
+	for _, item := range l.items {
+		if item.selectable() && item.Value {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// SelectAll selects every item of a multi-select list which may be
+// selected
+func (l *List) SelectAll() {
+	l.selectAll(true)
+}
+
+// ClearSelection deselects every item of a multi-select list
+func (l *List) ClearSelection() {
+	l.selectAll(false)
+}
+
+func (l *List) selectAll(on bool) {
+	for _, item := range l.items {
+		if item.selectable() {
+			item.Value = on
+		}
+	}
+	l.Redraw()
+}
+
+// Pattern returns the text typed to match an item
+func (l *List) Pattern() string {
+	return string(l.pattern)
+}
+
+// SetPattern makes the first item matching the pattern current. Items match
+// if the first line of their text begins with the pattern, ignoring case.
+func (l *List) SetPattern(pattern string) error {
+	i := l.match([]rune(pattern), 0, 1)
+	if i < 0 {
+		return listError(C.E_NO_MATCH)
+	}
+	l.pattern = []rune(pattern)
+	l.setCurrent(i)
+	return nil
+}
+
+// Driver performs a menu driver request, such as REQ_DOWN, on the list. A
+// printable character other than a request or a KEY_* code is added to the
+// pattern used to match items.
+func (l *List) Driver(req int) error {
+	var err C.int = C.E_OK
+	switch req {
+	case REQ_DOWN, REQ_UP, REQ_NEXT, REQ_PREV, REQ_FIRST, REQ_LAST:
+		err = l.move(req)
+	case int(REQ_LEFT), REQ_RIGHT:
+		err = C.E_REQUEST_DENIED
+	case REQ_DLINE:
+		err = l.scroll(1)
+	case REQ_ULINE:
+		err = l.scroll(-1)
+	case REQ_PAGE_DOWN, REQ_PAGE_UP:
+		h, _ := l.win.MaxYX()
+		if req == REQ_PAGE_UP {
+			h = -h
+		}
+		err = l.scroll(h)
+	case REQ_TOGGLE:
+		cur := l.Current()
+		if !l.multi || cur == nil {
+			err = C.E_REQUEST_DENIED
+			break
+		}
+		cur.Value = !cur.Value
+		l.Redraw()
+	case REQ_ACTIVATE:
+		return l.doActivate()
+	case REQ_CLEAR_PATTERN:
+		l.pattern = nil
+	case REQ_BACK_PATTERN:
+		if len(l.pattern) == 0 {
+			err = C.E_REQUEST_DENIED
+			break
+		}
+		l.pattern = l.pattern[:len(l.pattern)-1]
+	case REQ_NEXT_MATCH, REQ_PREV_MATCH:
+		dir := 1
+		if req == REQ_PREV_MATCH {
+			dir = -1
+		}
+		if i := l.match(l.pattern, l.current+dir, dir); i >= 0 {
+			l.setCurrent(i)
+		} else {
+			err = C.E_NO_MATCH
+		}
+	default:
+		if req >= KEY_MIN && req <= KEY_MAX || req > utf8.MaxRune ||
+			!unicode.IsPrint(rune(req)) {
+			err = C.E_UNKNOWN_COMMAND
+			break
+		}
+		err = l.addPattern(rune(req))
+	}
+	return listError(err)
+}
+
+// HandleKey handles the key k according to the list's keymap, returning
+// true if it was used, as Menu.HandleKey does. Keys not in the keymap which
+// may be typed, including multi-byte UTF-8 characters, are used to match
+// items and KEY_MOUSE events are passed to HandleMouse. If the key maps to
+// REQ_ACTIVATE the function set by OnActivate is called. Space toggles an
+// item only in a multi-select list, otherwise it is part of the pattern.
+func (l *List) HandleKey(k Key) (bool, error) {
+	keys := MenuKeys
+	if l.keys != nil {
+		keys = l.keys
+	}
+	req, ok := keys[k]
+	switch {
+	case k >= 0x80 && k < 0x100:
+		l.partial = append(l.partial, byte(k))
+		if !utf8.FullRune(l.partial) {
+			return true, nil
+		}
+		r, _ := utf8.DecodeRune(l.partial)
+		l.partial = nil
+		// The rune may lie among the KEY_* codes, which Driver rejects
+		return true, listError(l.addPattern(r))
+	case req == REQ_TOGGLE && k >= ' ' && k < 127 && !l.multi:
+		req = int(k)
+	case ok:
+	case k == KEY_MOUSE:
+		return l.HandleMouse(GetMouse()), nil
+	case k >= ' ' && k < 127:
+		req = int(k)
+	default:
+		return false, nil
+	}
+	l.partial = nil
+	return true, l.Driver(req)
+}
+
+// HandleMouse handles a mouse event as a menu does, returning true if it
+// was used. Clicking on an item makes it current and double clicking
+// activates it. Clicking above or below the list's window scrolls up or
+// down a page and turning the mouse wheel over the window scrolls by three
+// rows.
+func (l *List) HandleMouse(ev *MouseEvent) bool {
+	if ev == nil {
+		return false
+	}
+	y, _, ok := l.win.MouseToLocal(ev)
+	if ev.WheelUp || ev.WheelDown {
+		if !ok {
+			return false
+		}
+		n := 3
+		if ev.WheelUp {
+			n = -3
+		}
+		l.scroll(n)
+		return true
+	}
+	if ev.Button != 1 || ev.Action != MOUSE_PRESSED &&
+		ev.Action != MOUSE_CLICKED && ev.Action != MOUSE_DOUBLE_CLICKED {
+		return false
+	}
+	wy, wx := l.win.YX()
+	h, w := l.win.MaxYX()
+	if !ok {
+		if ev.X < wx || ev.X >= wx+w {
+			return false
+		}
+		switch {
+		case ev.Y == wy-1:
+			l.scroll(-h)
+		case ev.Y == wy+h:
+			l.scroll(h)
+		default:
+			return false
+		}
+		return true
+	}
+	i := l.itemAt(l.top + y)
+	if i < 0 || !l.items[i].selectable() {
+		return true
+	}
+	l.pattern = nil
+	l.setCurrent(i)
+	if ev.Action == MOUSE_DOUBLE_CLICKED {
+		l.doActivate()
+	}
+	return true
+}
+
+// Redraw clears the window and draws the rows of the list which are shown
+func (l *List) Redraw() {
+	h, w := l.win.MaxYX()
+	attr, pair := l.win.Attr()
+	defer l.win.AttrSet(Char(attr) | ColorPair(pair))
+
+	markW := StringWidth(l.Mark)
+	checkW := 0
+	if l.multi {
+		checkW = max(StringWidth(l.Checked), StringWidth(l.Unchecked)) + 1
+	}
+	prefixW := 0
+	for _, item := range l.items {
+		prefixW = max(prefixW, StringWidth(item.Prefix))
+	}
+	if prefixW > 0 {
+		prefixW++
+	}
+
+	l.win.Erase()
+	row := 0
+	for i, item := range l.items {
+		for j, line := range item.lines() {
+			y := row - l.top
+			row++
+			if y < 0 || y >= h {
+				continue
+			}
+			style := item.Style.Attr | ColorPair(item.Style.Pair)
+			if item.Separator {
+				l.win.AttrSet(style)
+				l.win.DrawHLine(y, 0, w, BORDER_SINGLE)
+				continue
+			}
+			if item.Header {
+				l.win.AttrSet(style | A_BOLD)
+				l.win.MovePrint(y, 0, Truncate(line, w, ""))
+				continue
+			}
+			if item.Disabled {
+				style |= l.Grey
+			}
+			l.win.AttrSet(Char(attr) | ColorPair(pair))
+			if i == l.current && j == 0 {
+				l.win.MovePrint(y, 0, Truncate(l.Mark, w, ""))
+			}
+			x := markW
+			if l.multi && j == 0 {
+				mark := l.Unchecked
+				if item.Value {
+					mark = l.Checked
+				}
+				l.win.MovePrint(y, min(x, w), Truncate(mark, max(w-x, 0), ""))
+			}
+			x += checkW
+			if i == l.current {
+				style |= l.Fore
+			}
+			if x >= w {
+				continue
+			}
+			l.win.AttrSet(style)
+			text := line
+			if j == 0 && prefixW > 0 {
+				text = item.Prefix +
+					strings.Repeat(" ", prefixW-StringWidth(item.Prefix)) + line
+			} else if prefixW > 0 {
+				text = strings.Repeat(" ", prefixW) + line
+			}
+			text = Truncate(text, w-x, "")
+			l.win.MovePrint(y, x, text+strings.Repeat(" ", w-x-StringWidth(text)))
+		}
+	}
+	if cur := l.current; cur >= 0 {
+		l.win.Move(clamp(l.rowOf(cur)-l.top, 0, h-1), 0)
+	}
+}
+
+// rowOf returns the first row of the item at index i
+func (l *List) rowOf(i int) int {
+	row := 0
+	for _, item := range l.items[:i] {
+		row += len(item.lines())
+	}
+	return row
+}
+
+// itemAt returns the index of the item shown in row, or -1 if there is none
+func (l *List) itemAt(row int) int {
+	for i, item := range l.items {
+		row -= len(item.lines())
+		if row < 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// next returns the index of the first item after i in the direction dir
+// which may be made current, or -1 if there is none
+func (l *List) next(i, dir int) int {
+	for i += dir; i >= 0 && i < len(l.items); i += dir {
+		if l.items[i].selectable() {
+			return i
+		}
+	}
+	return -1
+}
+
+// setCurrent makes the item at index i current and scrolls the list to
+// show it
+func (l *List) setCurrent(i int) {
+	changed := i != l.current
+	l.current = i
+	l.show()
+	l.Redraw()
+	if changed && l.itemChange != nil {
+		l.itemChange(l.Current())
+	}
+}
+
+// show scrolls the list, if need be, so that the current item is shown
+func (l *List) show() {
+	if l.current < 0 {
+		return
+	}
+	h, _ := l.win.MaxYX()
+	start := l.rowOf(l.current)
+	end := start + len(l.items[l.current].lines())
+	if end > l.top+h {
+		l.top = end - h
+	}
+	if start < l.top {
+		l.top = start
+	}
+}
+
+// move handles the requests which move between items
+func (l *List) move(req int) C.int {
+	if l.current < 0 {
+		return C.E_REQUEST_DENIED
+	}
+	i := -1
+	switch req {
+	case REQ_DOWN, REQ_NEXT:
+		if i = l.next(l.current, 1); i < 0 && req == REQ_NEXT {
+			i = l.next(-1, 1)
+		}
+	case REQ_UP, REQ_PREV:
+		if i = l.next(l.current, -1); i < 0 && req == REQ_PREV {
+			i = l.next(len(l.items), -1)
+		}
+	case REQ_FIRST:
+		i = l.next(-1, 1)
+	case REQ_LAST:
+		i = l.next(len(l.items), -1)
+	}
+	if i < 0 {
+		return C.E_REQUEST_DENIED
+	}
+	l.pattern = nil
+	l.setCurrent(i)
+	return C.E_OK
+}
+
+// scroll scrolls the list by n rows, down if n is positive, making the
+// nearest item shown current if the current item scrolls out of view
+func (l *List) scroll(n int) C.int {
+	h, _ := l.win.MaxYX()
+	rows := l.rowOf(len(l.items))
+	top := clamp(l.top+n, 0, max(rows-h, 0))
+	if top == l.top {
+		return C.E_REQUEST_DENIED
+	}
+	l.top = top
+	l.pattern = nil
+	i := l.current
+	if i >= 0 && !l.shown(i) {
+		// The first item wholly shown below, or last above, the current one
+		dir := 1
+		if l.rowOf(i) >= top {
+			dir = -1
+		}
+		for i = l.next(i, dir); i >= 0 && !l.shown(i); i = l.next(i, dir) {
+		}
+	}
+	changed := i >= 0 && i != l.current
+	if changed {
+		l.current = i
+	}
+	l.Redraw()
+	if changed && l.itemChange != nil {
+		l.itemChange(l.items[i])
+	}
+	return C.E_OK
+}
+
+// shown returns true if all the rows of the item at index i are shown
+func (l *List) shown(i int) bool {
+	h, _ := l.win.MaxYX()
+	start := l.rowOf(i)
+	return start >= l.top && start+len(l.items[i].lines()) <= l.top+h
+}
+
+// addPattern adds r to the pattern if an item matches the result,
+// searching from the current item
+func (l *List) addPattern(r rune) C.int {
+	pattern := append(l.pattern[:len(l.pattern):len(l.pattern)], r)
+	i := l.match(pattern, max(l.current, 0), 1)
+	if i < 0 {
+		return C.E_NO_MATCH
+	}
+	l.pattern = pattern
+	l.setCurrent(i)
+	return C.E_OK
+}
+
+// match returns the index of the first item from index i in the direction
+// dir, wrapping around, which matches the pattern, or -1 if none does
+func (l *List) match(pattern []rune, i, dir int) int {
+	n := len(l.items)
+	if n == 0 || len(pattern) == 0 {
+		return -1
+	}
+	p := strings.ToLower(string(pattern))
+	for j := 0; j < n; j++ {
+		k := ((i+j*dir)%n + n) % n
+		item := l.items[k]
+		if item.selectable() &&
+			strings.HasPrefix(strings.ToLower(item.lines()[0]), p) {
+			return k
+		}
+	}
+	return -1
+}
+
+// doActivate calls the list's OnActivate function with the current item
+func (l *List) doActivate() error {
+	if l.current < 0 {
+		return listError(C.E_NOT_CONNECTED)
+	}
+	if l.activate != nil {
+		l.activate(l.items[l.current])
+	}
+	return nil
+}
+
+// listError returns the error of the menu library's error code err
+func listError(err C.int) error {
+	return ncursesError(syscall.Errno(err))
+}
@@ -0,0 +1,44 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestListPatternInput(t *testing.T) {
+	win, err := goncurses.NewWindow(5, 20, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer win.Delete()
+	list := goncurses.NewList([]*goncurses.ListItem{{Text: "apple"},
+		{Text: "ładny"}, {Text: "łóżko"}, {Text: "ĉapelo"}}, win)
+
+	// KEY_* codes are not characters of the pattern, though KEY_F1 is also
+	// the code point of ĉ
+	for _, k := range []goncurses.Key{goncurses.KEY_F1, goncurses.KEY_HOME,
+		goncurses.KEY_MIN, goncurses.KEY_MAX, 0x7f, 0x85} {
+		if err := list.Driver(int(k)); err == nil || list.Pattern() != "" {
+			t.Errorf("Driver(%#o) added to the pattern, now %q", k,
+				list.Pattern())
+		}
+	}
+	// A character among the KEY_* codes may still be typed
+	for _, k := range []byte("łó") {
+		if _, err := list.HandleKey(goncurses.Key(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cur := list.Current(); list.Pattern() != "łó" || cur == nil ||
+		cur.Text != "łóżko" {
+		t.Errorf("typing łó matched %v with pattern %q", cur, list.Pattern())
+	}
+}
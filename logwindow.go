@@ -0,0 +1,177 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// LogWindow displays a scrolling log of lines in a window, keeping a bounded
+// history of lines which have scrolled off the top so that they can be paged
+// back through. New lines are appended at the bottom using the terminal's
+// scrolling capabilities where possible, which is far cheaper than redrawing
+// the whole window.
+type LogWindow struct {
+	win    *Window
+	lines  []string
+	max    int
+	offset int
+}
+
+// NewLogWindow creates a LogWindow which draws to the window w and retains
+// up to size lines of history, including those currently visible. The
+// window's scrolling region is set to cover the entire window.
+func NewLogWindow(w *Window, size int) *LogWindow {
+	h, _ := w.MaxYX()
+	w.IdlOk(true)
+	w.SetScrollRegion(0, h-1)
+	if size < h {
+		size = h
+	}
+	return &LogWindow{win: w, max: size}
+}
+
+// Window returns the window the log is drawn to
+func (l *LogWindow) Window() *Window {
+	return l.win
+}
+
+// Len returns the number of lines held in the log's history
+func (l *LogWindow) Len() int {
+	return len(l.lines)
+}
+
+// Offset returns the number of lines the log has been scrolled back by. A
+// value of zero means the most recent lines are visible.
+func (l *LogWindow) Offset() int {
+	return l.offset
+}
+
+// Append adds text to the bottom of the log. The text is split into lines at
+// each newline and lines too wide for the window are broken across
+// several. If the log has been scrolled back the view is left where it is,
+// otherwise the window is scrolled to show the new lines. Like other drawing
+// functions, the window must be refreshed for the changes to appear.
+func (l *LogWindow) Append(text string) {
+	h, width := l.win.MaxYX()
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		for {
+			var head string
+			head, line = cutWidth(line, width)
+			if head == "" && line != "" {
+				// Too narrow for even a single character
+				_, n := utf8.DecodeRuneInString(line)
+				head, line = line[:n], line[n:]
+			}
+			l.appendLine(h, head)
+			if line == "" {
+				break
+			}
+		}
+	}
+}
+
+func (l *LogWindow) appendLine(h int, line string) {
+	l.lines = append(l.lines, line)
+	// Reslicing leaves append to drop the trimmed lines the next time it
+	// has to grow the underlying array, so the history is never copied on
+	// every line
+	trimmed := len(l.lines) > l.max
+	if trimmed {
+		l.lines = l.lines[1:]
+	}
+	if l.offset > 0 {
+		// Keep the view still unless its top line fell out of the history
+		l.offset++
+		if trimmed && l.offset > len(l.lines)-h {
+			l.Redraw()
+		}
+		return
+	}
+	y := len(l.lines) - 1
+	if y >= h {
+		// Scrolling must be allowed, but the caller's setting is kept
+		ok := l.win.IsScrollOk()
+		l.win.ScrollOk(true)
+		l.win.Scroll(1)
+		l.win.ScrollOk(ok)
+		y = h - 1
+	}
+	l.win.MovePrint(y, 0, line)
+}
+
+// Redraw clears the window and redraws the visible portion of the log.
+// Call Redraw after the window has been resized or drawn over.
+func (l *LogWindow) Redraw() {
+	h, _ := l.win.MaxYX()
+	if max := len(l.lines) - h; l.offset > max {
+		l.offset = max
+	}
+	if l.offset < 0 {
+		l.offset = 0
+	}
+	end := len(l.lines) - l.offset
+	start := end - h
+	if start < 0 {
+		start = 0
+	}
+	l.win.Erase()
+	for i, line := range l.lines[start:end] {
+		l.win.MovePrint(i, 0, line)
+	}
+}
+
+// ScrollBack scrolls the view of the log back through its history by n
+// lines, or forward towards the most recent lines if n is negative. The
+// view stops at either end of the history.
+func (l *LogWindow) ScrollBack(n int) {
+	l.offset += n
+	l.Redraw()
+}
+
+// HandleKey scrolls the log in response to the page up and page down keys,
+// moving by a page less one line. The home and end keys move to the start
+// and end of the history. It returns true if the key was handled.
+func (l *LogWindow) HandleKey(k Key) bool {
+	h, _ := l.win.MaxYX()
+	page := h - 1
+	if page < 1 {
+		page = 1
+	}
+	switch k {
+	case KEY_PAGEUP:
+		l.ScrollBack(page)
+	case KEY_PAGEDOWN:
+		l.ScrollBack(-page)
+	case KEY_HOME:
+		l.ScrollBack(len(l.lines))
+	case KEY_END:
+		l.ScrollBack(-len(l.lines))
+	default:
+		return false
+	}
+	return true
+}
+
+// HandleMouse scrolls the log by three lines in response to the mouse wheel
+// being turned while the pointer is over the log's window. It returns true
+// if the event was handled. Wheel events are only reported when the mouse
+// mask includes them, as M_ALL does.
+func (l *LogWindow) HandleMouse(ev *MouseEvent) bool {
+	if ev == nil || !l.win.Enclose(ev.Y, ev.X) {
+		return false
+	}
+	switch {
+	case ev.WheelUp:
+		l.ScrollBack(3)
+	case ev.WheelDown:
+		l.ScrollBack(-3)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestLogWindowKeepsScrollOk(t *testing.T) {
+	for _, ok := range []bool{false, true} {
+		win, err := goncurses.NewWindow(3, 20, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		win.ScrollOk(ok)
+		log := goncurses.NewLogWindow(win, 10)
+		log.Append("one\ntwo\nthree\nfour\nfive")
+		if got := win.IsScrollOk(); got != ok {
+			t.Errorf("ScrollOk(%t) changed to %t by appending", ok, got)
+		}
+		if got := win.MoveInChar(0, 0) & goncurses.A_CHARTEXT; got != 't' {
+			t.Errorf("top line starts with %q, want 't' of three", got)
+		}
+		win.Delete()
+	}
+}
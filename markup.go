@@ -0,0 +1,233 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+// #include <curses.h>
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// StyledRun is a span of text sharing the same attributes and colors. It is
+// produced by SprintMarkup and printed by PrintRuns.
+type StyledRun struct {
+	Text   string
+	Attr   Char  // attributes OR'd together, like A_BOLD|A_UNDERLINE
+	Fg, Bg int16 // colors; C_DEFAULT leaves the window's color unchanged
+}
+
+// Width returns the number of columns the run occupies on the terminal
+func (r StyledRun) Width() int {
+	return StringWidth(r.Text)
+}
+
+var markupAttrs = map[string]Char{
+	"normal":    A_NORMAL,
+	"standout":  A_STANDOUT,
+	"underline": A_UNDERLINE,
+	"reverse":   A_REVERSE,
+	"blink":     A_BLINK,
+	"dim":       A_DIM,
+	"bold":      A_BOLD,
+	"italic":    A_ITALIC,
+	"invis":     A_INVIS,
+	"protect":   A_PROTECT,
+}
+
+var markupColors = map[string]int16{
+	"default": C_DEFAULT,
+	"black":   C_BLACK,
+	"red":     C_RED,
+	"green":   C_GREEN,
+	"yellow":  C_YELLOW,
+	"blue":    C_BLUE,
+	"magenta": C_MAGENTA,
+	"cyan":    C_CYAN,
+	"white":   C_WHITE,
+}
+
+// SprintMarkup parses text containing inline style tags and returns it as
+// a slice of styled runs without printing it. This is useful for measuring
+// the width of styled text before printing it with PrintRuns. The syntax
+// is as follows:
+//
+// A tag is enclosed in square brackets and contains one or more styles
+// separated by commas or spaces. A style is either the name of an attribute
+// (normal, standout, underline, reverse, blink, dim, bold, italic, invis or
+// protect) or a color setting of the form fg=color or bg=color. A color is
+// one of black, red, green, yellow, blue, magenta, cyan, white, default or
+// a color number. Styles are added to those already in effect; normal
+// clears any attributes set by enclosing tags.
+//
+// The runs are styled relative to the window they are printed on: default
+// keeps the window's color, which need not be the terminal's default, and
+// normal can't clear the window's own attributes, which PrintRuns adds to
+// those of every run.
+//
+// The tag [/] restores the style in effect prior to the most recent,
+// unclosed tag. Tags left open at the end of the text are closed
+// implicitly.
+//
+// A literal opening bracket is written as [[. A closing bracket outside of
+// a tag needs no escaping.
+//
+// 	"[bold]Error:[/] [fg=red,underline]disk full[/] [[100%]"
+func SprintMarkup(markup string) ([]StyledRun, error) {
+	var runs []StyledRun
+	var text strings.Builder
+	stack := []StyledRun{{Fg: C_DEFAULT, Bg: C_DEFAULT}}
+
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		run := stack[len(stack)-1]
+		run.Text = text.String()
+		runs = append(runs, run)
+		text.Reset()
+	}
+	for i := 0; i < len(markup); i++ {
+		if markup[i] != '[' {
+			text.WriteByte(markup[i])
+			continue
+		}
+		if strings.HasPrefix(markup[i+1:], "[") {
+			text.WriteByte('[')
+			i++
+			continue
+		}
+		end := strings.IndexByte(markup[i:], ']')
+		if end < 0 {
+			return nil, fmt.Errorf("Unterminated markup tag at offset %d", i)
+		}
+		flush()
+		tag := markup[i+1 : i+end]
+		if tag == "/" {
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("Unmatched markup tag [/] at offset %d",
+					i)
+			}
+			stack = stack[:len(stack)-1]
+		} else {
+			style, err := parseMarkupTag(stack[len(stack)-1], tag)
+			if err != nil {
+				return nil, fmt.Errorf("%s at offset %d", err, i)
+			}
+			stack = append(stack, style)
+		}
+		i += end
+	}
+	flush()
+	return runs, nil
+}
+
+// parseMarkupTag applies the styles listed in tag to style
+func parseMarkupTag(style StyledRun, tag string) (StyledRun, error) {
+	styles := strings.FieldsFunc(tag, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if len(styles) == 0 {
+		return style, errors.New("Empty markup tag")
+	}
+	for _, s := range styles {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok {
+			attr, ok := markupAttrs[s]
+			if !ok {
+				return style, fmt.Errorf("Unknown markup style %q", s)
+			}
+			if attr == A_NORMAL {
+				style.Attr = A_NORMAL
+			}
+			style.Attr |= attr
+			continue
+		}
+		color, ok := markupColors[value]
+		if !ok {
+			n, err := strconv.ParseInt(value, 10, 16)
+			if err != nil || n < 0 {
+				return style, fmt.Errorf("Unknown markup color %q", value)
+			}
+			color = int16(n)
+		}
+		switch key {
+		case "fg":
+			style.Fg = color
+		case "bg":
+			style.Bg = color
+		default:
+			return style, fmt.Errorf("Unknown markup style %q", s)
+		}
+	}
+	return style, nil
+}
+
+// PrintMarkup prints text containing inline style tags to the window. See
+// SprintMarkup for a description of the syntax. Nothing is printed if the
+// markup is malformed.
+func (w *Window) PrintMarkup(markup string) error {
+	runs, err := SprintMarkup(markup)
+	if err != nil {
+		return err
+	}
+	return w.PrintRuns(runs)
+}
+
+// MovePrintMarkup moves the cursor to the specified coordinates and prints
+// the marked up text. See PrintMarkup for more details.
+func (w *Window) MovePrintMarkup(y, x int, markup string) error {
+	runs, err := SprintMarkup(markup)
+	if err != nil {
+		return err
+	}
+	w.Move(y, x)
+	return w.PrintRuns(runs)
+}
+
+// PrintRuns prints each run using its attributes in addition to those
+// currently set on the window. Colors are mapped onto color pairs obtained
+// from AllocPair; any color left as C_DEFAULT is taken from the window's
+// current color pair. Colors are ignored if StartColor has not been called.
+// The window's attributes are restored once all runs are printed.
+func (w *Window) PrintRuns(runs []StyledRun) error {
+	attr, pair := w.Attr()
+	defer C.wattr_set(w.win, C.attr_t(attr), C.short(pair), nil)
+
+	for _, run := range runs {
+		p, err := stylePair(pair, run.Fg, run.Bg)
+		if err != nil {
+			return err
+		}
+		C.wattr_set(w.win, C.attr_t(Char(attr)|run.Attr), C.short(p), nil)
+		w.Print(run.Text)
+	}
+	return nil
+}
+
+// stylePair returns a color pair for the colors fg and bg, either of which
+// may be C_DEFAULT to use the corresponding color of pair instead. If both
+// are C_DEFAULT, or StartColor has not been called, pair is returned.
+func stylePair(pair, fg, bg int16) (int16, error) {
+	if (fg == C_DEFAULT && bg == C_DEFAULT) || C.COLOR_PAIRS <= 0 {
+		return pair, nil
+	}
+	pfg, pbg, err := PairContent(pair)
+	if err != nil {
+		return pair, err
+	}
+	if fg == C_DEFAULT {
+		fg = pfg
+	}
+	if bg == C_DEFAULT {
+		bg = pbg
+	}
+	return AllocPair(fg, bg)
+}
@@ -7,17 +7,123 @@
 package goncurses
 
 /*
-#cgo pkg-config: menu
+#cgo !ncursesw pkg-config: menu
+#cgo ncursesw pkg-config: menuw
 #include <menu.h>
+#include <stdint.h>
 #include <stdlib.h>
+#include <string.h>
 
 ITEM* menu_item_at(ITEM** ilist, int i) {
 	return ilist[i];
+}
+
+static uintptr_t item_handle(const ITEM *item) {
+	return (uintptr_t)item_userptr(item);
+}
+
+static int set_item_handle(ITEM *item, uintptr_t h) {
+	return set_item_userptr(item, (void *)h);
+}
+
+static uintptr_t menu_handle(const MENU *menu) {
+	return (uintptr_t)menu_userptr(menu);
+}
+
+static int set_menu_handle(MENU *menu, uintptr_t h) {
+	return set_menu_userptr(menu, (void *)h);
+}
+
+// Returns the number of columns of the menu's mark
+static int menu_mark_len(const MENU *menu) {
+	const char *mark = menu_mark(menu);
+	return mark != NULL ? (int)strlen(mark) : 0;
+}
+
+// Finds the line and column of an item's name in the sub window of its
+// menu, after the mark, returning ERR if the item is not shown. The layout
+// is worked out as the menu library does, using only its public functions:
+// the items fill rows, or columns without O_ROWMAJOR, of the number of
+// columns set by the menu's format, each as wide as the widest item.
+static int menu_item_yx(const MENU *menu, const ITEM *item, int *y, int *x) {
+	int n = item_count(menu), i = item_index(item);
+	int frows, fcols, rows, cols, height, width, itemlen;
+	int spc_desc, spc_rows, spc_cols, row, col;
+
+	if (n <= 0 || i == ERR || !item_visible(item))
+		return ERR;
+	menu_format(menu, &frows, &fcols);
+	menu_spacing(menu, &spc_desc, &spc_rows, &spc_cols);
+	if (scale_menu(menu, &height, &width) != E_OK)
+		return ERR;
+	rows = (n - 1) / fcols + 1;
+	if (menu_opts(menu) & O_ROWMAJOR) {
+		cols = n < fcols ? n : fcols;
+		row = i / cols;
+		col = i % cols;
+	} else {
+		cols = (n - 1) / rows + 1;
+		row = i % rows;
+		col = i / rows;
+	}
+	itemlen = (width - (cols - 1) * spc_cols) / cols;
+	*y = (row - top_row(menu)) * spc_rows;
+	*x = col * (spc_cols + itemlen) + menu_mark_len(menu);
+	return OK;
+}
+
+// Draws the check marks of the visible items of a menu over the menu's
+// mark, which the menu only draws for the current item. The marks are drawn
+// in the attributes of their items.
+static void menu_draw_checks(MENU *menu, const char *on, const char *off) {
+	WINDOW *sub = menu_sub(menu);
+	ITEM **items = menu_items(menu);
+	int marklen = menu_mark_len(menu);
+	attr_t attrs;
+	short pair;
+	int i, y, x;
+
+	if (items == NULL)
+		return;
+	wattr_get(sub, &attrs, &pair, NULL);
+	for (i = 0; items[i] != NULL; i++) {
+		ITEM *item = items[i];
+		chtype attr = menu_back(menu);
+
+		if (menu_item_yx(menu, item, &y, &x) != OK)
+			continue;
+		if (!(item_opts(item) & O_SELECTABLE))
+			attr = menu_grey(menu);
+		else if (item_value(item) || item == current_item(menu))
+			attr = menu_fore(menu);
+		wattrset(sub, attr);
+		mvwaddstr(sub, y, x - marklen, item_value(item) ? on : off);
+	}
+	wattr_set(sub, attrs, pair, NULL);
+	pos_menu_cursor(menu);
+}
+
+// Adds attr to the attributes of n columns of the name of an item of the
+// menu, starting at column col of the name, if the item is shown
+static void item_highlight(const MENU *menu, const ITEM *item, int col, int n,
+	attr_t attr) {
+	WINDOW *sub;
+	chtype ch;
+	int y, x;
+
+	if (menu_item_yx(menu, item, &y, &x) != OK)
+		return;
+	sub = menu_sub(menu);
+	ch = mvwinch(sub, y, x + col);
+	mvwchgat(sub, y, x + col, n, (ch & A_ATTRIBUTES & ~A_COLOR) | attr,
+		PAIR_NUMBER(ch), NULL);
 }*/
 import "C"
 
 import (
+	"strings"
 	"syscall"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -26,20 +132,48 @@ type Menu struct {
 }
 
 type MenuItem struct {
-	item *C.ITEM
+	item       *C.ITEM
+	name, desc *C.char
 }
 
-// NewMenu returns a pointer to a new menu.
-func NewMenu(items []*MenuItem) (*Menu, error) {
-	citems := make([]*C.ITEM, len(items)+1)
+// menuItems maps the items created by NewItem to their MenuItem, so that
+// the items of a menu are returned as the same Go values
+var menuItems = make(map[*C.ITEM]*MenuItem)
+
+// lookupItem returns the MenuItem of the C item
+func lookupItem(item *C.ITEM) *MenuItem {
+	if mi, ok := menuItems[item]; ok {
+		return mi
+	}
+	return &MenuItem{item: item}
+}
+
+// itemArray returns a NULL terminated array of the items in C memory, as
+// the menu keeps the array for as long as it has the items
+func itemArray(items []*MenuItem) **C.ITEM {
+	size := C.size_t(unsafe.Sizeof((*C.ITEM)(nil)))
+	p := (**C.ITEM)(C.calloc(C.size_t(len(items)+1), size))
+	citems := unsafe.Slice(p, len(items)+1)
 	for index, item := range items {
 		citems[index] = item.item
 	}
-	citems[len(items)] = nil
+	return p
+}
+
+// NewMenu returns a pointer to a new menu. The menu does not own the items,
+// which must be freed after the menu, unless it is released with Close.
+func NewMenu(items []*MenuItem) (*Menu, error) {
+	citems := itemArray(items)
 	var menu *C.MENU
 	var err error
-	menu, err = C.new_menu((**C.ITEM)(&citems[0]))
-	return &Menu{menu}, ncursesError(err)
+	menu, err = C.new_menu(citems)
+	if menu == nil {
+		C.free(unsafe.Pointer(citems))
+		return nil, ncursesError(err)
+	}
+	m := &Menu{menu}
+	m.data(true).citems = citems
+	return m, nil
 }
 
 // RequestName of menu request code
@@ -63,6 +197,27 @@ func (m *Menu) Background() int {
 	return int(C.menu_back(m.menu))
 }
 
+// Close releases the menu, unposting it first if it is posted, and then
+// frees its items. Neither the menu nor its items may be used afterwards.
+func (m *Menu) Close() error {
+	if d := m.data(false); d != nil && d.posted {
+		if err := m.UnPost(); err != nil {
+			return err
+		}
+	}
+	items := m.Items()
+	if err := m.Free(); err != nil {
+		return err
+	}
+	var err error
+	for _, item := range items {
+		if e := item.Free(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
 // Count returns the number of MenuItems in the Menu
 func (m *Menu) Count() int {
 	return int(C.item_count(m.menu))
@@ -71,17 +226,234 @@ func (m *Menu) Count() int {
 // Current returns the selected item in the menu
 func (m *Menu) Current(mi *MenuItem) *MenuItem {
 	if mi == nil {
-		return &MenuItem{C.current_item(m.menu)}
+		return lookupItem(C.current_item(m.menu))
+	}
+	if d := m.data(false); d != nil {
+		d.pattern = ""
 	}
 	C.set_current_item(m.menu, mi.item)
+	m.decorate()
 	return nil
 }
 
 // Driver controls how the menu is activated. Action usually corresponds
 // to the string return by the Key() function in goncurses.
 func (m *Menu) Driver(daction int) error {
-	err := C.menu_driver(m.menu, C.int(daction))
-	return ncursesError(syscall.Errno(err))
+	return ncursesError(syscall.Errno(m.driver(daction)))
+}
+
+// HandleKey handles the key k according to the menu's keymap, returning
+// true if it was used. Keys not in the keymap which may be typed into the
+// menu's pattern buffer are used to match items, including the bytes of a
+// multi-byte character, and KEY_MOUSE is passed to HandleMouse with the
+// event read by GetMouse. If the key maps to REQ_ACTIVATE, or an item is
+// double clicked, the function set by OnActivate is called. Space toggles
+// an item only if O_ONEVALUE is off, otherwise it is part of the pattern.
+// The error is that of the menu driver, such as when there is no item
+// matching the pattern.
+func (m *Menu) HandleKey(k Key) (bool, error) {
+	keys := MenuKeys
+	if d := m.data(false); d != nil && d.keys != nil {
+		keys = d.keys
+	}
+	req, ok := keys[k]
+	printable := k >= ' ' && k < 127
+	switch {
+	case k >= 0x80 && k < 0x100:
+		d := m.data(true)
+		d.partial = append(d.partial, byte(k))
+		if !utf8.FullRune(d.partial) {
+			return true, nil
+		}
+		r, _ := utf8.DecodeRune(d.partial)
+		d.partial = nil
+		return true, ncursesError(syscall.Errno(m.addPattern(r)))
+	case req == REQ_TOGGLE && printable && m.Options()&O_ONEVALUE != 0:
+		req = int(k)
+	case ok:
+	case k == KEY_MOUSE:
+		return m.HandleMouse(GetMouse())
+	case printable:
+		req = int(k)
+	default:
+		return false, nil
+	}
+	if d := m.data(false); d != nil {
+		d.partial = nil
+	}
+	if req == REQ_ACTIVATE {
+		return true, m.activate()
+	}
+	err := m.driver(req)
+	return err != C.E_UNKNOWN_COMMAND, ncursesError(syscall.Errno(err))
+}
+
+// HandleMouse handles a mouse event as the menu driver would, returning true
+// if it was used. Clicking on an item makes it current and double clicking
+// it calls the function set by OnActivate. Clicking on the menu's window
+// above or below its sub window scrolls up or down a line, or a page if
+// double clicked, and turning the mouse wheel over the window scrolls by a
+// line. Events already read by GetMouse should be passed here, rather than
+// KEY_MOUSE to HandleKey.
+func (m *Menu) HandleMouse(ev *MouseEvent) (bool, error) {
+	if ev == nil || !m.Window().Enclose(ev.Y, ev.X) {
+		return false, nil
+	}
+	switch {
+	case ev.WheelUp:
+		return true, m.Driver(REQ_ULINE)
+	case ev.WheelDown:
+		return true, m.Driver(REQ_DLINE)
+	case ev.Button != 1 || ev.Action != MOUSE_PRESSED &&
+		ev.Action != MOUSE_CLICKED && ev.Action != MOUSE_DOUBLE_CLICKED:
+		return false, nil
+	}
+	sub := &Window{C.menu_sub(m.menu)}
+	y, x, ok := sub.MouseToLocal(ev)
+	if !ok {
+		sy, _ := sub.YX()
+		req := REQ_DLINE
+		switch {
+		case ev.Y < sy && ev.Action == MOUSE_DOUBLE_CLICKED:
+			req = REQ_PAGE_UP
+		case ev.Y < sy:
+			req = REQ_ULINE
+		case ev.Action == MOUSE_DOUBLE_CLICKED:
+			req = REQ_PAGE_DOWN
+		}
+		return true, m.Driver(req)
+	}
+	item := m.itemAt(y, x)
+	if item == nil {
+		return true, nil
+	}
+	m.Current(item)
+	if ev.Action == MOUSE_DOUBLE_CLICKED {
+		return true, m.activate()
+	}
+	return true, nil
+}
+
+// itemAt returns the item shown at line y and column x of the menu's sub
+// window, counting its mark as part of it, or nil if there is none
+func (m *Menu) itemAt(y, x int) *MenuItem {
+	marklen := int(C.menu_mark_len(m.menu))
+	var found *MenuItem
+	fx := -1
+	for _, item := range m.Items() {
+		iy, ix, ok := m.position(item)
+		if ok && iy == y && ix-marklen <= x && ix > fx {
+			found, fx = item, ix
+		}
+	}
+	return found
+}
+
+// addPattern adds r, which the menu driver can only match if it is an
+// ASCII character, to the menu's pattern if an item matches the result,
+// making the item current. A pattern the menu driver can't hold is kept in
+// the menu's data until a request other than one for the pattern is made.
+func (m *Menu) addPattern(r rune) C.int {
+	pattern := m.Pattern() + string(r)
+	item := m.match(pattern, 0)
+	if item == nil {
+		return C.E_NO_MATCH
+	}
+	C.set_current_item(m.menu, item)
+	m.setPattern(pattern)
+	m.decorate()
+	return C.E_OK
+}
+
+// driver makes a request of the menu driver, returning its error code. While
+// the menu's pattern is one the menu driver can't hold, requests to edit or
+// match the pattern are handled here instead.
+func (m *Menu) driver(req int) C.int {
+	if d := m.data(false); d != nil && d.pattern != "" {
+		switch {
+		case req == REQ_BACK_PATTERN:
+			_, n := utf8.DecodeLastRuneInString(d.pattern)
+			m.setPattern(d.pattern[:len(d.pattern)-n])
+			C.pos_menu_cursor(m.menu)
+			return C.E_OK
+		case req == REQ_NEXT_MATCH, req == REQ_PREV_MATCH:
+			dir := 1
+			if req == REQ_PREV_MATCH {
+				dir = -1
+			}
+			item := m.match(d.pattern, dir)
+			if item == nil {
+				return C.E_NO_MATCH
+			}
+			pattern := d.pattern
+			C.set_current_item(m.menu, item)
+			m.setPattern(pattern)
+			m.decorate()
+			return C.E_OK
+		case req >= ' ' && req < 127:
+			return m.addPattern(rune(req))
+		}
+		d.pattern = ""
+	}
+	err := C.menu_driver(m.menu, C.int(req))
+	m.decorate()
+	return err
+}
+
+// setPattern sets the pattern of a menu whose current item already matches
+// it, keeping it in the menu's data if the menu driver can't hold it
+func (m *Menu) setPattern(pattern string) {
+	d := m.data(true)
+	d.pattern = ""
+	cpattern := C.CString(pattern)
+	defer C.free(unsafe.Pointer(cpattern))
+	if C.set_menu_pattern(m.menu, cpattern) != C.E_OK && pattern != "" {
+		d.pattern = pattern
+	}
+}
+
+// match returns the first item after the current item in the direction dir,
+// or from the current item if dir is zero, wrapping around, whose name
+// begins with the pattern ignoring case, or nil if none does
+func (m *Menu) match(pattern string, dir int) *C.ITEM {
+	n := int(C.item_count(m.menu))
+	cur := C.current_item(m.menu)
+	if n <= 0 || cur == nil {
+		return nil
+	}
+	p := strings.ToLower(pattern)
+	i := int(C.item_index(cur)) + dir
+	if dir == 0 {
+		dir = 1
+	}
+	items := C.menu_items(m.menu)
+	for j := 0; j < n; j++ {
+		item := C.menu_item_at(items, C.int(((i+j*dir)%n+n)%n))
+		name := C.GoString(C.item_name(item))
+		if strings.HasPrefix(strings.ToLower(name), p) {
+			return item
+		}
+	}
+	return nil
+}
+
+// activate calls the menu's OnActivate function with the current item
+func (m *Menu) activate() error {
+	item := C.current_item(m.menu)
+	var err C.int = C.E_OK
+	switch {
+	case item == nil:
+		err = C.E_NOT_CONNECTED
+	case C.item_opts(item)&O_SELECTABLE == 0:
+		err = C.E_NOT_SELECTABLE
+	}
+	if err != C.E_OK {
+		return ncursesError(syscall.Errno(err))
+	}
+	if d := m.data(false); d != nil && d.activate != nil {
+		d.activate(lookupItem(item))
+	}
+	return nil
 }
 
 // Foreground gets the attributes of highlighted items in the menu
@@ -96,10 +468,19 @@ func (m *Menu) Format(r, c int) error {
 }
 
 // Free deallocates memory set aside for the menu. This must be called
-// before exiting.
+// before exiting. The menu must not be posted. Its items are not freed, see
+// Close.
 func (m *Menu) Free() error {
+	h := C.menu_handle(m.menu)
 	err := C.free_menu(m.menu)
-	m = nil
+	if err == C.E_OK {
+		deleteHandle(uintptr(h))
+		if d := m.data(false); d != nil {
+			C.free(unsafe.Pointer(d.citems))
+			delete(menuDataMap, m.menu)
+		}
+		m.menu = nil
+	}
 	return ncursesError(syscall.Errno(err))
 }
 
@@ -114,11 +495,20 @@ func (m *Menu) Items() []*MenuItem {
 	count := m.Count()
 	mitems := make([]*MenuItem, count)
 	for index := 0; index < count; index++ {
-		mitems[index] = &MenuItem{C.menu_item_at(citems, C.int(index))}
+		mitems[index] = lookupItem(C.menu_item_at(citems, C.int(index)))
 	}
 	return mitems
 }
 
+// hasItem returns true if the item belongs to the menu. The menu library
+// has no function returning an item's menu, so its index is looked up in the
+// menu's items instead.
+func (m *Menu) hasItem(mi *MenuItem) bool {
+	i := C.item_index(mi.item)
+	return i != C.ERR && int(i) < m.Count() &&
+		C.menu_item_at(C.menu_items(m.menu), i) == mi.item
+}
+
 // Mark sets the indicator for the currently selected menu item
 func (m *Menu) Mark(mark string) error {
 	cmark := C.CString(mark)
@@ -130,7 +520,7 @@ func (m *Menu) Mark(mark string) error {
 
 // Option sets the options for the menu. See the O_* definitions for
 // a list of values which can be OR'd together
-func (m *Menu) Option(opts int, on bool) error {
+func (m *Menu) Option(opts MenuOption, on bool) error {
 	var err C.int
 	if on {
 		err = C.menu_opts_on(m.menu, C.Menu_Options(opts))
@@ -140,6 +530,11 @@ func (m *Menu) Option(opts int, on bool) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// Options returns the options set for the menu
+func (m *Menu) Options() MenuOption {
+	return MenuOption(C.menu_opts(m.menu))
+}
+
 // Pad sets the padding character for menu items.
 func (m *Menu) Pad() int {
 	return int(C.menu_pad(m.menu))
@@ -147,6 +542,9 @@ func (m *Menu) Pad() int {
 
 // Pattern returns the menu's pattern buffer
 func (m *Menu) Pattern() string {
+	if d := m.data(false); d != nil && d.pattern != "" {
+		return d.pattern
+	}
 	return C.GoString(C.menu_pattern(m.menu))
 }
 
@@ -157,7 +555,23 @@ func (m *Menu) PositionCursor() {
 
 // Post the menu, making it visible
 func (m *Menu) Post() error {
+	// Posting clears the values of the items, which are the selection of a
+	// multi-select menu
+	var selected []*MenuItem
+	if m.Options()&O_ONEVALUE == 0 {
+		selected = m.Selected()
+	}
+	d := m.data(true)
+	d.posting = true
 	err := C.post_menu(m.menu)
+	d.posting = false
+	if err == C.E_OK {
+		d.posted = true
+		for _, item := range selected {
+			C.set_item_value(item.item, true)
+		}
+		m.decorate()
+	}
 	return ncursesError(syscall.Errno(err))
 }
 
@@ -182,14 +596,111 @@ func (m *Menu) SetForeground(ch Char) error {
 }
 
 // SetItems will either set the items in the menu. When setting
-// items you must make sure the prior menu items will be freed.
+// items you must make sure the prior menu items will be freed. In a
+// multi-select menu, the new items with the names of items which were
+// selected are selected.
 func (m *Menu) SetItems(items []*MenuItem) error {
-	citems := make([]*C.ITEM, len(items)+1)
-	for index, item := range items {
-		citems[index] = item.item
+	var selected map[string]bool
+	if m.Options()&O_ONEVALUE == 0 {
+		selected = make(map[string]bool)
+		for _, item := range m.Selected() {
+			selected[item.Name()] = true
+		}
+	}
+	citems := itemArray(items)
+	err := C.set_menu_items(m.menu, citems)
+	if err != C.E_OK {
+		C.free(unsafe.Pointer(citems))
+		return ncursesError(syscall.Errno(err))
+	}
+	d := m.data(true)
+	C.free(unsafe.Pointer(d.citems))
+	d.citems = citems
+	if len(selected) > 0 {
+		for _, item := range items {
+			if selected[item.Name()] {
+				C.set_item_value(item.item, true)
+			}
+		}
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetCheckMarks sets the marks drawn beside the items of a multi-select
+// menu to show whether they are selected, such as "[x]" and "[ ]". The
+// menu's mark is set to unchecked, so the current item is shown by its
+// highlighting. The shorter mark is padded with spaces to the width of the
+// longer. An empty checked mark stops the drawing of check marks.
+func (m *Menu) SetCheckMarks(checked, unchecked string) error {
+	w := max(StringWidth(checked), StringWidth(unchecked))
+	if checked != "" {
+		checked += strings.Repeat(" ", w-StringWidth(checked))
+		unchecked += strings.Repeat(" ", w-StringWidth(unchecked))
+	}
+	if err := m.Mark(unchecked); err != nil {
+		return err
+	}
+	d := m.data(true)
+	d.checked, d.unchecked = checked, unchecked
+	m.decorate()
+	return nil
+}
+
+// SetKeyMap sets the keymap used by HandleKey, mapping keys to menu driver
+// requests. A nil keymap restores the default, MenuKeys.
+func (m *Menu) SetKeyMap(keys map[Key]int) {
+	m.data(true).keys = keys
+}
+
+// SetMultiSelect sets whether more than one item of the menu may be
+// selected, by turning off the O_ONEVALUE option. Items are selected by
+// toggling them with REQ_TOGGLE.
+func (m *Menu) SetMultiSelect(on bool) error {
+	return m.Option(O_ONEVALUE, !on)
+}
+
+// Selected returns the selected items of a multi-select menu, in the order
+// of the menu's items. For other menus it returns the current item, if any.
+func (m *Menu) Selected() []*MenuItem {
+	var items []*MenuItem
+	if m.Options()&O_ONEVALUE != 0 {
+		if item := C.current_item(m.menu); item != nil {
+			items = append(items, lookupItem(item))
+		}
+		return items
+	}
+	for _, item := range m.Items() {
+		if item.Value() {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// SelectAll selects every selectable item of a multi-select menu
+func (m *Menu) SelectAll() error {
+	return m.selectAll(true)
+}
+
+// ClearSelection deselects every item of a multi-select menu
+func (m *Menu) ClearSelection() error {
+	return m.selectAll(false)
+}
+
+func (m *Menu) selectAll(on bool) error {
+	var err C.int = C.E_OK
+	if m.Options()&O_ONEVALUE != 0 {
+		err = C.E_REQUEST_DENIED
+		return ncursesError(syscall.Errno(err))
+	}
+	for _, item := range m.Items() {
+		if C.item_opts(item.item)&O_SELECTABLE != 0 {
+			if e := C.set_item_value(item.item, C.bool(on)); e != C.E_OK {
+				err = e
+			}
+		}
 	}
-	citems[len(items)] = nil
-	err := C.set_menu_items(m.menu, (**C.ITEM)(&citems[0]))
+	m.decorate()
 	return ncursesError(syscall.Errno(err))
 }
 
@@ -201,9 +712,13 @@ func (m *Menu) SetPad(ch Char) error {
 
 // SetPattern sets the padding character for menu items.
 func (m *Menu) SetPattern(pattern string) error {
+	if d := m.data(false); d != nil {
+		d.pattern = ""
+	}
 	cpattern := C.CString(pattern)
 	defer C.free(unsafe.Pointer(cpattern))
 	err := C.set_menu_pattern(m.menu, (*C.char)(cpattern))
+	m.decorate()
 	return ncursesError(syscall.Errno(err))
 }
 
@@ -219,6 +734,19 @@ func (m *Menu) SetSpacing(desc, row, col int) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// SetUserData attaches the value v to the menu, replacing any value
+// previously attached. The value is released when the menu is freed.
+func (m *Menu) SetUserData(v interface{}) error {
+	old, h := C.menu_handle(m.menu), newHandle(v)
+	err := C.set_menu_handle(m.menu, C.uintptr_t(h))
+	if err != C.E_OK {
+		deleteHandle(h)
+	} else {
+		deleteHandle(uintptr(old))
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
 // SetWindow container for the menu
 func (m *Menu) SetWindow(w *Window) error {
 	err := C.set_menu_win(m.menu, w.win)
@@ -240,12 +768,42 @@ func (m *Menu) SubWindow(sub *Window) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// decorate draws what goncurses adds to a posted menu, which must be done
+// each time the menu may have redrawn its items: the check marks of a
+// multi-select menu and the highlighting of a FilterMenu
+func (m *Menu) decorate() {
+	d := m.data(false)
+	if d == nil || !d.posted {
+		return
+	}
+	if d.checked != "" && m.Options()&O_ONEVALUE == 0 {
+		on, off := C.CString(d.checked), C.CString(d.unchecked)
+		C.menu_draw_checks(m.menu, on, off)
+		C.free(unsafe.Pointer(on))
+		C.free(unsafe.Pointer(off))
+	}
+	if d.decorate != nil {
+		d.decorate()
+	}
+}
+
 // UnPost the menu, effectively hiding it.
 func (m *Menu) UnPost() error {
+	d := m.data(true)
+	d.unposting = true
 	err := C.unpost_menu(m.menu)
+	d.unposting = false
+	if err == C.E_OK {
+		d.posted = false
+	}
 	return ncursesError(syscall.Errno(err))
 }
 
+// UserData returns the value attached to the menu by SetUserData or nil
+func (m *Menu) UserData() interface{} {
+	return handleValue(uintptr(C.menu_handle(m.menu)))
+}
+
 // Window container for the menu. Returns nil on failure
 func (m *Menu) Window() *Window {
 	return &Window{C.menu_win(m.menu)}
@@ -259,7 +817,14 @@ func NewItem(name, desc string) (*MenuItem, error) {
 	var item *C.ITEM
 	var err error
 	item, err = C.new_item(cname, cdesc)
-	return &MenuItem{item}, ncursesError(err)
+	if item == nil {
+		C.free(unsafe.Pointer(cname))
+		C.free(unsafe.Pointer(cdesc))
+		return nil, ncursesError(err)
+	}
+	mi := &MenuItem{item, cname, cdesc}
+	menuItems[item] = mi
+	return mi, nil
 }
 
 // Description returns the second value passed to NewItem
@@ -267,10 +832,34 @@ func (mi *MenuItem) Description() string {
 	return C.GoString(C.item_description(mi.item))
 }
 
-// Free must be called on all menu items to avoid memory leaks
-func (mi *MenuItem) Free() {
-	C.free(unsafe.Pointer(C.item_name(mi.item)))
-	C.free_item(mi.item)
+// Free must be called on all menu items to avoid memory leaks. An item
+// may not be freed while it belongs to a menu, so its menu must be freed
+// first.
+func (mi *MenuItem) Free() error {
+	h := C.item_handle(mi.item)
+	err := C.free_item(mi.item)
+	if err == C.E_OK {
+		deleteHandle(uintptr(h))
+		delete(menuItems, mi.item)
+		C.free(unsafe.Pointer(mi.name))
+		C.free(unsafe.Pointer(mi.desc))
+		mi.item, mi.name, mi.desc = nil, nil, nil
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// position returns the line and column of the name of an item of the
+// menu in the menu's sub window, after the mark, and whether it is shown
+func (m *Menu) position(mi *MenuItem) (y, x int, ok bool) {
+	var cy, cx C.int
+	ok = C.menu_item_yx(m.menu, mi.item, &cy, &cx) == C.OK
+	return int(cy), int(cx), ok
+}
+
+// highlight adds attr to the attributes of n columns of the name of an item
+// of the menu, starting at column col, if the item is shown
+func (m *Menu) highlight(mi *MenuItem, col, n int, attr Char) {
+	C.item_highlight(m.menu, mi.item, C.int(col), C.int(n), C.attr_t(attr))
 }
 
 // Index of the menu item in it's parent menu
@@ -292,9 +881,27 @@ func (mi *MenuItem) Selectable(on bool) {
 	}
 }
 
+// SetUserData attaches the value v to the item, replacing any value
+// previously attached. The value is released when the item is freed.
+func (mi *MenuItem) SetUserData(v interface{}) error {
+	old, h := C.item_handle(mi.item), newHandle(v)
+	err := C.set_item_handle(mi.item, C.uintptr_t(h))
+	if err != C.E_OK {
+		deleteHandle(h)
+	} else {
+		deleteHandle(uintptr(old))
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
 // SetValue sets whether an item is active or not
 func (mi *MenuItem) SetValue(val bool) error {
 	err := int(C.set_item_value(mi.item, C.bool(val)))
+	for _, d := range menuDataMap {
+		if d.posted && d.menu.hasItem(mi) {
+			d.menu.decorate()
+		}
+	}
 	return ncursesError(syscall.Errno(err))
 }
 
@@ -303,6 +910,11 @@ func (mi *MenuItem) Value() bool {
 	return bool(C.item_value(mi.item))
 }
 
+// UserData returns the value attached to the item by SetUserData or nil
+func (mi *MenuItem) UserData() interface{} {
+	return handleValue(uintptr(C.item_handle(mi.item)))
+}
+
 // Visible returns true if the item is visible, false if not
 func (mi *MenuItem) Visible() bool {
 	return bool(C.item_visible(mi.item))
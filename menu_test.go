@@ -0,0 +1,332 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+// TestMain runs the tests with a screen writing to the null device, as
+// menus may only be created once curses is initialized
+func TestMain(m *testing.M) {
+	null, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	scr, err := goncurses.NewTerm("xterm", null, null)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	scr.End()
+	scr.Delete()
+	null.Close()
+	os.Exit(code)
+}
+
+func newItems(t *testing.T, n int, desc string) []*goncurses.MenuItem {
+	items := make([]*goncurses.MenuItem, n)
+	for i := range items {
+		item, err := goncurses.NewItem(fmt.Sprintf("item %d", i), desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items[i] = item
+	}
+	return items
+}
+
+func TestMenuItemsIdentity(t *testing.T) {
+	items := newItems(t, 3, "")
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Close()
+	for i, item := range menu.Items() {
+		if item != items[i] {
+			t.Errorf("Items()[%d] is not the item passed to NewMenu", i)
+		}
+	}
+	if cur := menu.Current(nil); cur != items[0] {
+		t.Errorf("Current is %v, want the first item", cur.Name())
+	}
+	if err := menu.SetItems(items[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if got := menu.Items(); len(got) != 2 || got[0] != items[1] {
+		t.Errorf("Items after SetItems are not the items set")
+	}
+	// The item removed from the menu is still owned by the caller
+	if err := items[0].Free(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMenuItemFreeConnected(t *testing.T) {
+	before, _ := goncurses.MenuState()
+	items := newItems(t, 1, "")
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The menu library refuses with E_CONNECTED to free an item in a menu
+	err = items[0].Free()
+	if err == nil || err.Error() != "Field is already connected to a form" {
+		t.Errorf("freeing an item belonging to a menu returned %v", err)
+	}
+	if items[0].Name() != "item 0" {
+		t.Error("item damaged by failing to free it")
+	}
+	if n, _ := goncurses.MenuState(); n != before+1 {
+		t.Errorf("holding %d items after failing to free one, want %d", n,
+			before+1)
+	}
+	if err := menu.Close(); err != nil {
+		t.Error(err)
+	}
+	if n, _ := goncurses.MenuState(); n != before {
+		t.Errorf("holding %d items after Close, want %d", n, before)
+	}
+}
+
+func TestMenuCloseLeaks(t *testing.T) {
+	items, menus := goncurses.MenuState()
+	for i := 0; i < 3; i++ {
+		menu, err := goncurses.NewMenu(newItems(t, 10, "description"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := menu.Post(); err != nil {
+			t.Fatal(err)
+		}
+		if i, m := goncurses.MenuState(); i != items+10 || m != menus+1 {
+			t.Fatalf("holding %d items and %d menus, want %d and %d",
+				i, m, items+10, menus+1)
+		}
+		// Closing a posted menu unposts it before freeing it and its items
+		if err := menu.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if i, m := goncurses.MenuState(); i != items || m != menus {
+			t.Fatalf("holding %d items and %d menus after Close, "+
+				"want %d and %d", i, m, items, menus)
+		}
+	}
+}
+
+func TestMenuBarClickEntry(t *testing.T) {
+	var chosen string
+	bar, err := goncurses.NewMenuBar([]goncurses.MenuEntry{
+		{Label: "&File", Entries: []goncurses.MenuEntry{
+			{Label: "&Open", Action: func() { chosen = "Open" }},
+			{Label: "&Quit", Action: func() { chosen = "Quit" }},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bar.Delete()
+	click := func(y, x int) bool {
+		return bar.HandleMouse(&goncurses.MouseEvent{Y: y, X: x, Button: 1,
+			Action: goncurses.MOUSE_CLICKED})
+	}
+	if !click(0, 1) || !bar.Active() {
+		t.Fatal("clicking the bar did not open its menu")
+	}
+	// The menu drops down below the bar inside a border, so its first
+	// entry is on the third line
+	if !click(2, 2) {
+		t.Fatal("click on the menu's first entry not used")
+	}
+	if chosen != "Open" {
+		t.Errorf("chose %q, want Open", chosen)
+	}
+	if bar.Active() {
+		t.Error("bar still active after choosing an entry")
+	}
+}
+
+func TestMenuHandleMouse(t *testing.T) {
+	items := newItems(t, 3, "")
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Close()
+	var activated *goncurses.MenuItem
+	menu.OnActivate(func(item *goncurses.MenuItem) { activated = item })
+	if err := menu.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer menu.UnPost()
+	// The event is passed in, as it has already been read by the caller
+	click := func(y int, action goncurses.MouseAction) {
+		ev := &goncurses.MouseEvent{Y: y, X: 3, Button: 1, Action: action}
+		if ok, err := menu.HandleMouse(ev); !ok || err != nil {
+			t.Fatalf("click on line %d: %v, %v", y, ok, err)
+		}
+	}
+	click(2, goncurses.MOUSE_CLICKED)
+	if cur := menu.Current(nil); cur != items[2] {
+		t.Errorf("clicked item 2, current is %s", cur.Name())
+	}
+	if activated != nil {
+		t.Error("single click activated an item")
+	}
+	click(1, goncurses.MOUSE_DOUBLE_CLICKED)
+	if activated != items[1] {
+		t.Error("double click did not activate item 1")
+	}
+}
+
+func TestMenuPatternNonASCII(t *testing.T) {
+	var items []*goncurses.MenuItem
+	for _, name := range []string{"apple", "eagle", "éclair", "Écru"} {
+		item, err := goncurses.NewItem(name, "")
+		if err != nil {
+			// The narrow library only takes names printable in the locale
+			for _, item := range items {
+				item.Free()
+			}
+			t.Skip("non-ASCII item names not supported:", err)
+		}
+		items = append(items, item)
+	}
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Close()
+	typeKeys := func(s string) {
+		for i := 0; i < len(s); i++ {
+			if ok, err := menu.HandleKey(goncurses.Key(s[i])); !ok || err != nil {
+				t.Fatalf("typing %q: %v, %v", s, ok, err)
+			}
+		}
+	}
+	typeKeys("éc")
+	if cur := menu.Current(nil); cur != items[2] || menu.Pattern() != "éc" {
+		t.Errorf("current %s with pattern %q, want éclair with éc",
+			cur.Name(), menu.Pattern())
+	}
+	if err := menu.Driver(goncurses.REQ_NEXT_MATCH); err != nil {
+		t.Error(err)
+	}
+	if cur := menu.Current(nil); cur != items[3] {
+		t.Errorf("next match is %s, want Écru", cur.Name())
+	}
+	if ok, _ := menu.HandleKey(goncurses.KEY_BACKSPACE); !ok ||
+		menu.Pattern() != "é" {
+		t.Errorf("pattern %q after backspace, want é", menu.Pattern())
+	}
+	if ok, err := menu.HandleKey('x'); !ok || err == nil {
+		t.Error("matched a pattern no item begins with")
+	}
+	menu.Driver(goncurses.REQ_FIRST)
+	if menu.Pattern() != "" {
+		t.Errorf("pattern %q kept after moving to the first item",
+			menu.Pattern())
+	}
+}
+
+func TestMenuBarEmpty(t *testing.T) {
+	bar, err := goncurses.NewMenuBar(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bar.Delete()
+	for _, k := range []goncurses.Key{goncurses.KEY_F10, goncurses.KEY_LEFT,
+		goncurses.KEY_RIGHT, goncurses.KEY_DOWN, goncurses.KEY_RETURN} {
+		if bar.HandleKey(k) {
+			t.Errorf("bar without entries used key %d", k)
+		}
+	}
+	if bar.Active() {
+		t.Error("bar without entries has focus")
+	}
+}
+
+func TestMenuBarKeys(t *testing.T) {
+	var chosen string
+	action := func(name string) func() {
+		return func() { chosen = name }
+	}
+	bar, err := goncurses.NewMenuBar([]goncurses.MenuEntry{
+		{Label: "&File", Entries: []goncurses.MenuEntry{
+			{Label: "&Open", Action: action("Open")},
+			{Separator: true},
+			{Label: "&Save", Action: action("Save"), Disabled: true},
+			{Label: "&Quit", Action: action("Quit")},
+		}},
+		{Label: "&Edit", Entries: []goncurses.MenuEntry{
+			{Label: "&Copy", Action: action("Copy")},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bar.Delete()
+	press := func(keys ...goncurses.Key) {
+		for _, k := range keys {
+			bar.HandleKey(k)
+		}
+	}
+	expect := func(what, want string, active bool) {
+		t.Helper()
+		if chosen != want {
+			t.Errorf("%s chose %q, want %q", what, chosen, want)
+		}
+		if bar.Active() != active {
+			t.Errorf("%s left the bar active %v, want %v", what,
+				bar.Active(), active)
+		}
+		chosen = ""
+	}
+
+	// Escape is left to the application while the bar is not focused
+	if bar.HandleKey(27) {
+		t.Error("Escape used while the bar is not focused")
+	}
+	press('x')
+	press(goncurses.KEY_F10, goncurses.KEY_LEFT, goncurses.KEY_RETURN,
+		goncurses.KEY_RETURN)
+	expect("moving left from File", "Copy", false)
+	// The bar is focused on the entry last highlighted
+	press(goncurses.KEY_F10, goncurses.KEY_RIGHT, goncurses.KEY_DOWN,
+		goncurses.KEY_RETURN)
+	expect("moving right from Edit", "Open", false)
+
+	// Moving down skips the separator and the disabled entry
+	press(goncurses.KEY_F10, goncurses.KEY_DOWN, goncurses.KEY_DOWN,
+		goncurses.KEY_RETURN)
+	expect("moving down from Open", "Quit", false)
+	press(goncurses.KEY_F10, goncurses.KEY_DOWN, goncurses.KEY_END,
+		goncurses.KEY_UP, goncurses.KEY_RETURN)
+	expect("moving up from Quit", "Open", false)
+
+	// Alt is Escape followed by the accelerator
+	press(27, 'f', 's')
+	expect("the accelerator of a disabled entry", "", true)
+	press('q')
+	expect("Alt+F then Q", "Quit", false)
+	press(27, 'E', 'c')
+	expect("Alt+E then C", "Copy", false)
+
+	// Escape closes the menu and then leaves the bar
+	press(goncurses.KEY_F10, goncurses.KEY_DOWN, 27)
+	expect("Escape from a menu", "", true)
+	press(27)
+	expect("Escape from the bar", "", false)
+}
@@ -0,0 +1,494 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MenuEntry describes an entry of a MenuBar or of one of its menus. A menu
+// bar is built from a tree of entries: the top level entries are shown on
+// the bar and the Entries of each make up its drop-down menu, whose entries
+// may in turn open submenus of their own.
+type MenuEntry struct {
+	// Label is the text of the entry. The character following an '&' is
+	// the entry's accelerator and is underlined. "&&" is a literal '&'.
+	Label string
+
+	// Hint is shown at the right of an entry of a drop-down menu, such as
+	// the shortcut key of a command. Entries with submenus show an arrow.
+	Hint string
+
+	// Action is called when the entry is activated, after the menus have
+	// closed. Entries with submenus open them instead.
+	Action func()
+
+	// Entries are the entries of the entry's submenu, if any
+	Entries []MenuEntry
+
+	// Disabled entries are shown dimmed and may not be activated
+	Disabled bool
+
+	// Separator makes the entry a line between groups of entries. Its
+	// other fields are ignored.
+	Separator bool
+}
+
+// MenuBar is a bar along the top line of the screen holding menus which
+// drop down from it. Each menu is a Menu in a window derived from a framed
+// window in a panel above the others.
+//
+// The bar is focused by its ActivateKey, by Alt and the accelerator of one
+// of its entries, or by clicking on an entry. While focused the bar handles
+// all keys: the arrow keys move between its entries and those of its menus,
+// Enter and the accelerators activate entries, and Escape closes the
+// innermost menu or leaves the bar. Alt is recognized as Escape followed
+// by the key, so HandleKey returns false for Escape while the bar is not
+// focused, letting the application also act on it.
+//
+// Keys are passed to HandleKey and mouse events to HandleMouse. The screen
+// should be updated with Refresh, which draws the bar.
+type MenuBar struct {
+	// ActivateKey focuses the bar, or leaves it. It defaults to F10.
+	ActivateKey Key
+
+	entries []MenuEntry
+	win     *Window
+	panel   *Panel
+	x       []int // columns of the text of the top level entries
+	current int   // highlighted top level entry
+	active  bool
+	menus   []*dropDown // menus open, outermost first
+	meta    bool        // Escape was pressed, so the next key is Alt+key
+}
+
+// dropDown is an open menu of a MenuBar
+type dropDown struct {
+	entries  []MenuEntry
+	items    []*MenuItem
+	menu     *Menu
+	win, sub *Window
+	panel    *Panel
+}
+
+// NewMenuBar creates a menu bar of the entries on the top line of the
+// screen
+func NewMenuBar(entries []MenuEntry) (*MenuBar, error) {
+	_, w := StdScr().MaxYX()
+	win, err := NewWindow(1, w, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	b := &MenuBar{ActivateKey: KEY_F10, entries: entries, win: win,
+		panel: NewPanel(win)}
+	x := 1
+	for _, e := range entries {
+		text, _, _ := parseLabel(e.Label)
+		b.x = append(b.x, x)
+		x += StringWidth(text) + 2
+	}
+	b.draw()
+	return b, nil
+}
+
+// Active returns true if the bar has focus
+func (b *MenuBar) Active() bool {
+	return b.active
+}
+
+// Refresh draws the bar and updates the screen
+func (b *MenuBar) Refresh() {
+	b.draw()
+	UpdatePanels()
+	Update()
+}
+
+// Delete closes any open menus and deletes the bar
+func (b *MenuBar) Delete() error {
+	b.closeMenus(0)
+	err := b.panel.Delete()
+	b.win.Delete()
+	return err
+}
+
+// HandleKey handles the key k, returning true if it was used. A bar without
+// entries uses no keys.
+func (b *MenuBar) HandleKey(k Key) bool {
+	if len(b.entries) == 0 {
+		return false
+	}
+	if !b.active {
+		meta := b.meta
+		b.meta = false
+		switch {
+		case k == 0:
+			return false
+		case k == b.ActivateKey:
+			b.focus(b.current)
+		case k == 27:
+			b.meta = true
+			return false
+		case meta && accelerator(b.entries, k) >= 0:
+			b.activateTop(accelerator(b.entries, k))
+		default:
+			return false
+		}
+		return true
+	}
+	if len(b.menus) == 0 {
+		switch n := len(b.entries); {
+		case k == KEY_LEFT:
+			b.focus((b.current + n - 1) % n)
+		case k == KEY_RIGHT:
+			b.focus((b.current + 1) % n)
+		case k == KEY_DOWN || isAccept(k):
+			b.activateTop(b.current)
+		case k == 27 || k == b.ActivateKey:
+			b.deactivate()
+		case accelerator(b.entries, k) >= 0:
+			b.activateTop(accelerator(b.entries, k))
+		}
+		return true
+	}
+
+	d := b.menus[len(b.menus)-1]
+	switch n := len(b.entries); {
+	case k == KEY_UP:
+		d.move(REQ_UP)
+	case k == KEY_DOWN:
+		d.move(REQ_DOWN)
+	case k == KEY_HOME:
+		d.menu.Driver(REQ_FIRST)
+		d.skip(REQ_DOWN)
+	case k == KEY_END:
+		d.menu.Driver(REQ_LAST)
+		d.skip(REQ_UP)
+	case k == KEY_LEFT && len(b.menus) > 1:
+		b.closeMenus(len(b.menus) - 1)
+	case k == KEY_LEFT:
+		b.activateTop((b.current + n - 1) % n)
+	case k == KEY_RIGHT && enabled(d.entries[d.current()]) &&
+		len(d.entries[d.current()].Entries) > 0:
+		b.openSubmenu()
+	case k == KEY_RIGHT:
+		b.activateTop((b.current + 1) % n)
+	case isAccept(k):
+		b.choose(d, d.current())
+	case k == 27:
+		b.closeMenus(len(b.menus) - 1)
+	case k == b.ActivateKey:
+		b.deactivate()
+	case accelerator(d.entries, k) >= 0:
+		b.choose(d, accelerator(d.entries, k))
+	}
+	return true
+}
+
+// HandleMouse handles clicks on the bar and its menus and the wheel within
+// its menus, returning true if the event was used. A click elsewhere while
+// the bar has focus closes its menus and is also used.
+func (b *MenuBar) HandleMouse(ev *MouseEvent) bool {
+	if ev == nil {
+		return false
+	}
+	for i := len(b.menus) - 1; i >= 0; i-- {
+		d := b.menus[i]
+		y, _, ok := d.win.MouseToLocal(ev)
+		if !ok {
+			continue
+		}
+		b.closeMenus(i + 1)
+		switch {
+		case ev.WheelUp:
+			d.move(REQ_UP)
+		case ev.WheelDown:
+			d.move(REQ_DOWN)
+		case ev.Button == 1 && (ev.Action == MOUSE_PRESSED ||
+			ev.Action == MOUSE_CLICKED):
+			for j, item := range d.items {
+				// Choosing may close the menu, freeing its items
+				if iy, _, ok := d.menu.position(item); ok && iy == y-1 {
+					b.choose(d, j)
+					break
+				}
+			}
+		}
+		return true
+	}
+	if ev.Button != 1 || ev.Action != MOUSE_PRESSED &&
+		ev.Action != MOUSE_CLICKED {
+		return false
+	}
+	if _, x, ok := b.win.MouseToLocal(ev); ok {
+		for i := range b.entries {
+			text, _, _ := parseLabel(b.entries[i].Label)
+			if x < b.x[i]-1 || x > b.x[i]+StringWidth(text) {
+				continue
+			}
+			if b.active && i == b.current && len(b.menus) > 0 {
+				b.deactivate()
+			} else {
+				b.activateTop(i)
+			}
+			return true
+		}
+	}
+	if b.active {
+		b.deactivate()
+		return true
+	}
+	return false
+}
+
+// focus gives the bar focus, highlighting the top level entry i
+func (b *MenuBar) focus(i int) {
+	b.closeMenus(0)
+	b.active, b.current = true, i
+	b.panel.Top()
+}
+
+func (b *MenuBar) deactivate() {
+	b.closeMenus(0)
+	b.active = false
+}
+
+// activateTop opens the menu of the top level entry i or, if it has none,
+// activates it
+func (b *MenuBar) activateTop(i int) {
+	b.focus(i)
+	e := b.entries[i]
+	switch {
+	case !enabled(e):
+	case len(e.Entries) == 0:
+		b.deactivate()
+		if e.Action != nil {
+			e.Action()
+		}
+	default:
+		if d, err := newDropDown(e.Entries, 1, b.x[i]-1, -1); err == nil {
+			b.menus = append(b.menus, d)
+		}
+	}
+}
+
+// openSubmenu opens the submenu of the current entry of the innermost menu
+// beside it
+func (b *MenuBar) openSubmenu() {
+	d := b.menus[len(b.menus)-1]
+	i := d.current()
+	y, _, _ := d.menu.position(d.items[i])
+	wy, wx := d.win.YX()
+	_, ww := d.win.MaxYX()
+	if sub, err := newDropDown(d.entries[i].Entries, wy+y, wx+ww,
+		wx); err == nil {
+		b.menus = append(b.menus, sub)
+	}
+}
+
+// choose activates the entry i of the menu d, opening its submenu if it has
+// one or otherwise closing the menus and calling its action
+func (b *MenuBar) choose(d *dropDown, i int) {
+	e := d.entries[i]
+	if !enabled(e) {
+		return
+	}
+	d.menu.Current(d.items[i])
+	if len(e.Entries) > 0 {
+		b.openSubmenu()
+		return
+	}
+	b.deactivate()
+	if e.Action != nil {
+		e.Action()
+	}
+}
+
+// closeMenus closes the open menus from the nth outwards
+func (b *MenuBar) closeMenus(n int) {
+	for len(b.menus) > n {
+		b.menus[len(b.menus)-1].close()
+		b.menus = b.menus[:len(b.menus)-1]
+	}
+}
+
+// draw draws the bar in reverse video, highlighting the current entry
+// while the bar has focus
+func (b *MenuBar) draw() {
+	_, w := b.win.MaxYX()
+	b.win.AttrSet(A_REVERSE)
+	b.win.HLine(0, 0, ' '|A_REVERSE, w)
+	for i, e := range b.entries {
+		text, _, col := parseLabel(e.Label)
+		attr := Char(A_REVERSE)
+		if b.active && i == b.current {
+			attr = A_NORMAL
+		}
+		if !enabled(e) {
+			attr |= A_DIM
+		}
+		b.win.AttrSet(attr)
+		b.win.MovePrint(0, b.x[i]-1, " "+text+" ")
+		if col >= 0 {
+			b.win.MoveChangeAttr(0, b.x[i]+col, 1, Attr(attr)|WA_UNDERLINE,
+				0)
+		}
+	}
+	b.win.AttrSet(A_NORMAL)
+}
+
+// newDropDown opens a menu of the entries with its top left corner at y, x.
+// If the menu does not fit to the right of x it is placed to the left of
+// the column left instead, if left is not negative, or moved left.
+func newDropDown(entries []MenuEntry, y, x, left int) (*dropDown, error) {
+	d := &dropDown{entries: entries}
+	for _, e := range entries {
+		name, desc := " ", ""
+		if !e.Separator {
+			name, _, _ = parseLabel(e.Label)
+			desc = e.Hint
+			if len(e.Entries) > 0 {
+				desc = ">"
+			}
+		}
+		item, err := NewItem(name, desc)
+		if err != nil {
+			d.freeItems()
+			return nil, err
+		}
+		item.Selectable(enabled(e))
+		d.items = append(d.items, item)
+	}
+	menu, err := NewMenu(d.items)
+	if err != nil {
+		d.freeItems()
+		return nil, err
+	}
+	d.menu = menu
+	menu.Mark(" ")
+	menu.Grey(A_DIM)
+	menu.Format(len(entries), 1)
+	rows, cols, _ := menu.Scale()
+	h, w := rows+2, cols+2
+	sh, sw := StdScr().MaxYX()
+	if x+w > sw {
+		if left >= 0 && left-w >= 0 {
+			x = left - w
+		} else {
+			x = sw - w
+		}
+	}
+	y, x = clamp(y, 0, sh-h), clamp(x, 0, sw-w)
+	if d.win, err = NewWindow(h, w, y, x); err != nil {
+		menu.Close()
+		return nil, err
+	}
+	d.sub = d.win.Derived(rows, cols, 1, 1)
+	d.panel = NewPanel(d.win)
+	d.win.Box(0, 0)
+	menu.SetWindow(d.win)
+	menu.SubWindow(d.sub)
+	menu.data(true).decorate = d.decorate
+	menu.Post()
+	d.skip(REQ_DOWN)
+	return d, nil
+}
+
+// current returns the index of the current entry
+func (d *dropDown) current() int {
+	return d.menu.Current(nil).Index()
+}
+
+// move moves to the next enabled entry in the direction of the request
+func (d *dropDown) move(req int) {
+	d.menu.Driver(req)
+	d.skip(req)
+}
+
+// skip moves in the direction of the request while the current entry is
+// disabled
+func (d *dropDown) skip(req int) {
+	for range d.entries {
+		if enabled(d.entries[d.current()]) {
+			return
+		}
+		d.menu.Driver(req)
+	}
+}
+
+// decorate underlines the accelerators and draws the separators across the
+// menu's frame
+func (d *dropDown) decorate() {
+	_, w := d.win.MaxYX()
+	for i, e := range d.entries {
+		y, _, ok := d.menu.position(d.items[i])
+		if !ok {
+			continue
+		}
+		if e.Separator {
+			d.win.MoveAddChar(y+1, 0, ACS_LTEE)
+			d.win.HLine(y+1, 1, ACS_HLINE, w-2)
+			d.win.MoveAddChar(y+1, w-1, ACS_RTEE)
+		} else if _, _, col := parseLabel(e.Label); col >= 0 {
+			d.menu.highlight(d.items[i], col, 1, A_UNDERLINE)
+		}
+	}
+}
+
+func (d *dropDown) close() {
+	d.menu.Close()
+	d.panel.Delete()
+	d.sub.Delete()
+	d.win.Delete()
+}
+
+func (d *dropDown) freeItems() {
+	for _, item := range d.items {
+		item.Free()
+	}
+}
+
+// enabled returns true if the entry may be activated
+func enabled(e MenuEntry) bool {
+	return !e.Separator && !e.Disabled
+}
+
+// parseLabel returns the text of a label without its '&' markers, and its
+// accelerator, in lower case, and the accelerator's column within the text.
+// The column is -1 if there is no accelerator.
+func parseLabel(label string) (text string, accel rune, col int) {
+	var b strings.Builder
+	col = -1
+	runes := []rune(label)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '&' && i+1 < len(runes) {
+			i++
+			if runes[i] != '&' && col < 0 {
+				accel = unicode.ToLower(runes[i])
+				col = StringWidth(b.String())
+			}
+		}
+		b.WriteRune(runes[i])
+	}
+	return b.String(), accel, col
+}
+
+// accelerator returns the index of the enabled entry whose accelerator is
+// the key k, or -1 if there is none
+func accelerator(entries []MenuEntry, k Key) int {
+	if k < ' ' || k >= 0x7f {
+		return -1
+	}
+	r := unicode.ToLower(rune(k))
+	for i, e := range entries {
+		if _, accel, _ := parseLabel(e.Label); enabled(e) && accel == r {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,147 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+/*
+#cgo !ncursesw pkg-config: menu
+#cgo ncursesw pkg-config: menuw
+#include <menu.h>
+
+extern void goncursesItemInit(MENU *);
+extern void goncursesMenuInit(MENU *);
+extern void goncursesMenuTerm(MENU *);
+*/
+import "C"
+
+import "syscall"
+
+// menuData holds the Go state of a menu, including the Go functions called
+// by its C hooks. The hooks are dispatched by the C menu to the exported
+// functions below, which look up the functions for that menu.
+type menuData struct {
+	menu       *Menu
+	itemChange func(*MenuItem)
+	pageChange func(*Menu)
+	post       func(*Menu)
+	unpost     func(*Menu)
+	activate   func(*MenuItem)
+	keys       map[Key]int
+
+	citems             **C.ITEM // the menu's item array
+	posted             bool
+	checked, unchecked string // check marks, set by SetCheckMarks
+	decorate           func() // called after the menu has drawn its items
+	pattern            string // a pattern the menu driver can't hold
+	partial            []byte // the bytes of a character being typed
+
+	// The C library calls the same hooks when a menu is posted and unposted
+	// as when its page changes, so Post and UnPost note what they are doing
+	posting, unposting bool
+}
+
+var menuDataMap = make(map[*C.MENU]*menuData)
+
+// data returns the menu's Go state, creating it if create is true
+func (m *Menu) data(create bool) *menuData {
+	h := menuDataMap[m.menu]
+	if h == nil && create {
+		h = &menuData{menu: m}
+		menuDataMap[m.menu] = h
+	}
+	return h
+}
+
+// OnItemChange sets the function called when the menu is posted and each
+// time its current item changes while posted. It is passed the new current
+// item. A nil function removes the hook.
+func (m *Menu) OnItemChange(fn func(*MenuItem)) error {
+	m.data(true).itemChange = fn
+	var hook C.Menu_Hook
+	if fn != nil {
+		hook = C.Menu_Hook(C.goncursesItemInit)
+	}
+	err := C.set_item_init(m.menu, hook)
+	return ncursesError(syscall.Errno(err))
+}
+
+// OnPageChange sets the function called when the items shown by the posted
+// menu change because it has scrolled to show another page or row of
+// items. A nil function removes the hook.
+func (m *Menu) OnPageChange(fn func(*Menu)) error {
+	m.data(true).pageChange = fn
+	return m.setMenuHooks()
+}
+
+// OnPost sets the function called after the menu is posted. A nil function
+// removes the hook.
+func (m *Menu) OnPost(fn func(*Menu)) error {
+	m.data(true).post = fn
+	return m.setMenuHooks()
+}
+
+// OnUnpost sets the function called before the menu is unposted. A nil
+// function removes the hook.
+func (m *Menu) OnUnpost(fn func(*Menu)) error {
+	m.data(true).unpost = fn
+	return m.setMenuHooks()
+}
+
+// OnActivate sets the function called by HandleKey when an item is
+// activated by the REQ_ACTIVATE request or double clicked. It is passed the
+// current item. A nil function removes the hook.
+func (m *Menu) OnActivate(fn func(*MenuItem)) {
+	m.data(true).activate = fn
+}
+
+// setMenuHooks installs or removes the menu's C init and term hooks
+// according to the Go functions set
+func (m *Menu) setMenuHooks() error {
+	h := m.data(true)
+	var init, term C.Menu_Hook
+	if h.pageChange != nil || h.post != nil {
+		init = C.Menu_Hook(C.goncursesMenuInit)
+	}
+	if h.unpost != nil {
+		term = C.Menu_Hook(C.goncursesMenuTerm)
+	}
+	if err := C.set_menu_init(m.menu, init); err != C.E_OK {
+		return ncursesError(syscall.Errno(err))
+	}
+	err := C.set_menu_term(m.menu, term)
+	return ncursesError(syscall.Errno(err))
+}
+
+//export goncursesItemInit
+func goncursesItemInit(menu *C.MENU) {
+	if h := menuDataMap[menu]; h != nil && h.itemChange != nil {
+		h.itemChange(lookupItem(C.current_item(menu)))
+	}
+}
+
+//export goncursesMenuInit
+func goncursesMenuInit(menu *C.MENU) {
+	h := menuDataMap[menu]
+	switch {
+	case h == nil:
+	case h.posting:
+		if h.post != nil {
+			h.post(h.menu)
+		}
+	case h.pageChange != nil:
+		h.pageChange(h.menu)
+	}
+}
+
+//export goncursesMenuTerm
+func goncursesMenuTerm(menu *C.MENU) {
+	// The term hook is also called before the page changes, which is
+	// reported after the change by the init hook instead
+	if h := menuDataMap[menu]; h != nil && h.unposting && h.unpost != nil {
+		h.unpost(h.menu)
+	}
+}
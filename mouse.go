@@ -5,38 +5,120 @@
 
 package goncurses
 
-// #cgo !windows pkg-config: ncurses
+// #cgo !windows,!ncursesw pkg-config: ncurses
+// #cgo !windows,ncursesw pkg-config: ncursesw
 // #cgo windows CFLAGS: -DNCURSES_MOUSE_VERSION
 // #cgo windows LDFLAGS: -lpdcurses
+// #include <stdlib.h>
 // #include <curses.h>
 // #include "goncurses.h"
 import "C"
 
 import (
+	"time"
 	"unsafe"
 )
 
+// MouseAction describes what happened to the button of a MouseEvent
+type MouseAction int
+
+const (
+	MOUSE_NONE           MouseAction = iota // no button changed state
+	MOUSE_PRESSED                           // button pressed
+	MOUSE_RELEASED                          // button released
+	MOUSE_CLICKED                           // button pressed and released
+	MOUSE_DOUBLE_CLICKED                    // button clicked twice
+	MOUSE_TRIPLE_CLICKED                    // button clicked three times
+	MOUSE_MOVED                             // pointer moved
+	MOUSE_DRAGGED                           // pointer moved with button held
+)
+
+// mouseActions holds the events for each button in the order of the
+// MouseAction values, starting with MOUSE_PRESSED
+var mouseActions = [...][5]MouseButton{
+	{M_B1_PRESSED, M_B1_RELEASED, M_B1_CLICKED, M_B1_DBL_CLICKED,
+		M_B1_TPL_CLICKED},
+	{M_B2_PRESSED, M_B2_RELEASED, M_B2_CLICKED, M_B2_DBL_CLICKED,
+		M_B2_TPL_CLICKED},
+	{M_B3_PRESSED, M_B3_RELEASED, M_B3_CLICKED, M_B3_DBL_CLICKED,
+		M_B3_TPL_CLICKED},
+	{M_B4_PRESSED, M_B4_RELEASED, M_B4_CLICKED, M_B4_DBL_CLICKED,
+		M_B4_TPL_CLICKED},
+	{M_B5_PRESSED, M_B5_RELEASED, M_B5_CLICKED, M_B5_DBL_CLICKED,
+		M_B5_TPL_CLICKED},
+}
+
+// MouseEvent describes a mouse event. State holds the raw event bits, which
+// are also decoded into the remaining fields. Motion events, reported when
+// the mouse mask includes M_POSITION, are MOUSE_DRAGGED while a button
+// pressed earlier is held down, in which case Button is that button.
 type MouseEvent struct {
 	Id      int16       /* device ID */
 	X, Y, Z int         /* event coordinates */
 	State   MouseButton /* button state */
+
+	Button             int         // button number, 1 to 5, or 0 if none
+	Action             MouseAction // what happened to the button
+	Shift, Ctrl, Alt   bool        // modifier keys held
+	WheelUp, WheelDown bool        // wheel turned (buttons 4 and 5)
+}
+
+var (
+	mouseMask  MouseButton   // events selected by MouseMask
+	mouseHeld  int           // button held down, for reporting drags
+	mouseQueue []*MouseEvent // events decoded by GetChar
+	mouseClick sgrClick      // click resolution of decoded events
+)
+
+// decode fills in the fields of the event derived from its State
+func (m *MouseEvent) decode() *MouseEvent {
+	m.Shift = m.State&M_SHIFT != 0
+	m.Ctrl = m.State&M_CTRL != 0
+	m.Alt = m.State&M_ALT != 0
+	for i, bits := range mouseActions {
+		for j, bit := range bits {
+			if m.State&bit != 0 {
+				m.Button, m.Action = i+1, MOUSE_PRESSED+MouseAction(j)
+			}
+		}
+	}
+	switch {
+	case m.Button == 0 && m.State&M_POSITION != 0:
+		m.Button, m.Action = mouseHeld, MOUSE_MOVED
+		if mouseHeld != 0 {
+			m.Action = MOUSE_DRAGGED
+		}
+	case m.Button >= 4:
+		m.WheelUp = m.Button == 4 && m.Action != MOUSE_RELEASED
+		m.WheelDown = m.Button == 5 && m.Action != MOUSE_RELEASED
+	case m.Action == MOUSE_PRESSED:
+		mouseHeld = m.Button
+	case m.Button == mouseHeld:
+		mouseHeld = 0
+	}
+	return m
 }
 
 // GetMouse returns the MouseEvent associated with a KEY_MOUSE event returned
 // by a call to GetChar(). Returns a new MouseEvent or nil on error or if no
 // event is currently in the mouse event queue
 func GetMouse() *MouseEvent {
+	if len(mouseQueue) > 0 {
+		m := mouseQueue[0]
+		mouseQueue = mouseQueue[1:]
+		return m
+	}
 	var event C.MEVENT
 	if C.ncurses_getmouse(&event) != C.OK {
 		return nil
 	}
-	return &MouseEvent{
+	return (&MouseEvent{
 		Id:    int16(event.id),
 		Y:     int(event.y),
 		X:     int(event.x),
 		Z:     int(event.z),
 		State: MouseButton(event.bstate),
-	}
+	}).decode()
 }
 
 // MouseOk returns true if ncurses has built-in mouse support. On ncurses 5.7
@@ -58,7 +140,167 @@ func MouseInterval(ms int) int {
 // is triggered, GetChar() will return KEY_MOUSE. To retrieve the actual
 // event use GetMouse() to pop it off the queue. Pass a pointer as the
 // second argument to store the prior events being monitored or nil.
+//
+// On xterm compatible terminals, M_POSITION enables reporting of motion
+// while a button is held, and SGR encoded reports are requested from
+// terminals described as using the older encoding so that events beyond
+// column or line 223 are reported.
 func MouseMask(mask MouseButton, old *MouseButton) MouseButton {
-	return MouseButton(C.mousemask((C.mmask_t)(mask),
+	if mouseMask&M_POSITION != 0 && mask&M_POSITION == 0 {
+		// Turning reporting off and on again is the only way to leave
+		// xterm's button-event tracking mode using the terminal's own
+		// sequence for enabling the mouse
+		C.mousemask(0, (*C.mmask_t)(unsafe.Pointer(old)))
+		old = nil
+	}
+	mouseMask = MouseButton(C.mousemask((C.mmask_t)(mask),
 		(*C.mmask_t)(unsafe.Pointer(old))))
+	xtermMouse(mouseMask)
+	return mouseMask
+}
+
+// keySGRMouse is returned by wgetch for the start of a mouse report in
+// xterm's SGR encoding when ncurses does not itself decode them. It lies
+// outside the range of key codes ncurses assigns.
+const keySGRMouse = 0xfff0
+
+// xtermMouse sets the xterm private modes needed to report the events in
+// mask which ncurses does not set itself
+func xtermMouse(mask MouseButton) {
+	kmous := C.ncurses_key_mouse()
+	if kmous == nil {
+		return
+	}
+	switch C.GoString(kmous) {
+	case "\033[M":
+		mode, key := "\033[?1006h", keySGRMouse
+		if mask == 0 {
+			mode, key = "\033[?1006l", 0
+		}
+		xtermMouseMode(mode, key)
+	case "\033[<":
+	default:
+		return
+	}
+	if mask&M_POSITION != 0 {
+		xtermMouseMode("\033[?1002h", -1)
+	}
+}
+
+func xtermMouseMode(mode string, key int) {
+	cmode := C.CString(mode)
+	defer C.free(unsafe.Pointer(cmode))
+	C.ncurses_xterm_mouse_mode(cmode, C.int(key))
+}
+
+// sgrClick holds the state needed to resolve presses and releases into
+// clicks
+type sgrClick struct {
+	button int
+	count  int
+	press  time.Time
+	last   time.Time
+}
+
+// sgrMouse reads the remainder of a mouse report in xterm's SGR encoding,
+// "\033[<b;x;yM", or 'm' in place of 'M' for a release, and queues the
+// event for GetMouse. It returns false if the report is malformed or the
+// event was not selected by MouseMask.
+func (w *Window) sgrMouse() bool {
+	var p [3]int
+	n := 0
+	for i := 0; i < 32; i++ {
+		ch := C.wgetch(w.win)
+		switch {
+		case ch >= '0' && ch <= '9':
+			p[n] = p[n]*10 + int(ch-'0')
+		case ch == ';' && n < 2:
+			n++
+		case (ch == 'M' || ch == 'm') && n == 2:
+			return queueSGRMouse(p[0], p[2]-1, p[1]-1, ch == 'm')
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// queueSGRMouse queues an event for the button code b of an SGR report.
+// Presses and releases are resolved into clicks like ncurses does when the
+// mask includes clicks but not the press or release itself.
+func queueSGRMouse(b, y, x int, release bool) bool {
+	var state MouseButton
+	switch {
+	case b&128 != 0: // buttons 8 to 11
+		return false
+	case b&64 != 0:
+		if b&3 > 1 {
+			return false
+		}
+		state = mouseActions[3+b&3][0]
+	case b&32 != 0:
+		state = M_POSITION
+	case b&3 == 3:
+		return false
+	default:
+		state = mouseClick.resolve(b&3+1, release)
+	}
+	if state&mouseMask == 0 {
+		return false
+	}
+	if b&4 != 0 {
+		state |= M_SHIFT
+	}
+	if b&8 != 0 {
+		state |= M_ALT
+	}
+	if b&16 != 0 {
+		state |= M_CTRL
+	}
+	mouseQueue = append(mouseQueue, (&MouseEvent{Y: y, X: x,
+		State: state & (mouseMask | M_SHIFT | M_ALT | M_CTRL)}).decode())
+	return true
+}
+
+// resolve returns the event for a press or release of the button
+func (c *sgrClick) resolve(button int, release bool) MouseButton {
+	bits := mouseActions[button-1]
+	now := time.Now()
+	interval := time.Duration(C.mouseinterval(-1)) * time.Millisecond
+	if !release {
+		if button != c.button || now.Sub(c.last) > interval {
+			c.count = 0
+		}
+		c.button, c.press = button, now
+		return bits[0]
+	}
+	clicks := bits[2] | bits[3] | bits[4]
+	if mouseMask&clicks == 0 || mouseMask&bits[1] != 0 ||
+		button != c.button || now.Sub(c.press) > interval {
+		c.count = 0
+		return bits[1]
+	}
+	if c.count < 3 {
+		c.count++
+	}
+	c.last = now
+	return bits[1+c.count]
+}
+
+// MouseToLocal translates the screen coordinates of a mouse event into
+// coordinates relative to the window's origin. The value of ok is false, and
+// y and x are the screen coordinates, if the event lies outside the window.
+func (w *Window) MouseToLocal(ev *MouseEvent) (y, x int, ok bool) {
+	cy, cx := C.int(ev.Y), C.int(ev.X)
+	ok = bool(C.wmouse_trafo(w.win, &cy, &cx, false))
+	return int(cy), int(cx), ok
+}
+
+// LocalToScreen translates coordinates relative to the window's origin into
+// screen coordinates, the inverse of MouseToLocal. The value of ok is false,
+// and y and x are unchanged, if the coordinates lie outside the window.
+func (w *Window) LocalToScreen(y, x int) (sy, sx int, ok bool) {
+	cy, cx := C.int(y), C.int(x)
+	ok = bool(C.wmouse_trafo(w.win, &cy, &cx, true))
+	return int(cy), int(cx), ok
 }
@@ -5,7 +5,10 @@
 
 package goncurses
 
-// #cgo !windows pkg-config: ncurses
+// #cgo !windows,!ncursesw pkg-config: ncurses
+// #cgo !windows,ncursesw pkg-config: ncursesw
+// #cgo !windows,ncursesw CFLAGS: -DNCURSES_WIDECHAR=1
+// #cgo windows,ncursesw CFLAGS: -DPDC_WIDE
 // #cgo windows CFLAGS: -DNCURSES_MOUSE_VERSION
 // #cgo windows LDFLAGS: -lpdcurses
 // #include <curses.h>
@@ -18,6 +21,40 @@ import (
 	"unsafe"
 )
 
+// setLocale sets the locale from the environment before curses is
+// initialized, as wide character support depends on it. It does nothing
+// unless built with the ncursesw tag.
+var setLocale = func() {}
+
+// allocPairs records the color pairs handed out by AllocPair. They belong
+// to the screen, so are forgotten by End.
+var allocPairs = make(map[[2]int16]int16)
+
+// AllocPair returns a color pair with the foreground and background colors
+// fg and bg, initializing a new pair the first time a combination is
+// requested. Pairs are allocated from the highest pair available downwards
+// so as not to clash with low numbered pairs set up via InitPair.
+// StartColor must have been called prior. Pairs are allocated afresh after
+// End, as a new screen may be created.
+func AllocPair(fg, bg int16) (int16, error) {
+	if pair, ok := allocPairs[[2]int16{fg, bg}]; ok {
+		return pair, nil
+	}
+	max := int(C.COLOR_PAIRS) - 1
+	if max > 0x7fff {
+		max = 0x7fff
+	}
+	pair := max - len(allocPairs)
+	if pair <= 0 {
+		return 0, errors.New("No color pairs left to allocate")
+	}
+	if err := InitPair(int16(pair), fg, bg); err != nil {
+		return 0, err
+	}
+	allocPairs[[2]int16{fg, bg}] = int16(pair)
+	return int16(pair), nil
+}
+
 // BaudRate returns the speed of the terminal in bits per second
 func BaudRate() int {
 	return int(C.baudrate())
@@ -91,6 +128,7 @@ func Echo(on bool) {
 // terminal returns to normal operation
 func End() {
 	C.endwin()
+	allocPairs = make(map[[2]int16]int16)
 }
 
 // Flash requests the terminal flashes the screen or, if not available,
@@ -169,8 +207,11 @@ func InitPair(pair, fg, bg int16) error {
 }
 
 // Initialize the ncurses library. You must run this function prior to any
-// other goncurses function in order for the library to work
+// other goncurses function in order for the library to work. When built
+// with the ncursesw tag the process's locale is first set from the
+// environment, as wide character support depends on it.
 func Init() (stdscr *Window, err error) {
+	setLocale()
 	stdscr = &Window{C.initscr()}
 	if unsafe.Pointer(stdscr.win) == nil {
 		err = errors.New("An error occurred initializing ncurses")
@@ -0,0 +1,39 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestAllocPairAfterEnd(t *testing.T) {
+	if err := goncurses.StartColor(); err != nil {
+		t.Skip(err)
+	}
+	// Forget the pairs allocated by other tests
+	goncurses.End()
+	first, err := goncurses.AllocPair(goncurses.C_RED, goncurses.C_BLUE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := goncurses.AllocPair(goncurses.C_RED,
+		goncurses.C_BLUE); p != first {
+		t.Errorf("same colors allocated pair %d, want %d", p, first)
+	}
+	// Pairs are allocated afresh from the highest after End
+	goncurses.End()
+	p, err := goncurses.AllocPair(goncurses.C_GREEN, goncurses.C_BLUE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != first {
+		t.Errorf("allocated pair %d after End, want %d", p, first)
+	}
+}
@@ -4,23 +4,52 @@
 
 package goncurses
 
-// #cgo !windows pkg-config: panel
+// #cgo !windows,!ncursesw pkg-config: panel
+// #cgo !windows,ncursesw pkg-config: panelw
+// #include <stdint.h>
 // #include <panel.h>
 // #include <curses.h>
+//
+// static uintptr_t panel_handle(const PANEL *pan) {
+// 	return (uintptr_t)panel_userptr(pan);
+// }
+// static int set_panel_handle(PANEL *pan, uintptr_t h) {
+// 	return set_panel_userptr(pan, (void *)h);
+// }
 import "C"
 
-import "errors"
+import (
+	"errors"
+	"iter"
+)
 
 type Panel struct {
 	pan *C.PANEL
 }
 
+// panels maps each C panel onto its Panel so that the same *Panel is
+// returned whenever a panel is looked up in the stack
+var panels = make(map[*C.PANEL]*Panel)
+
+// lookupPanel returns the Panel for pan or nil if pan is nil
+func lookupPanel(pan *C.PANEL) *Panel {
+	if pan == nil {
+		return nil
+	}
+	p, ok := panels[pan]
+	if !ok {
+		p = &Panel{pan}
+		panels[pan] = p
+	}
+	return p
+}
+
 // Panel creates a new panel derived from the window, adding it to the
 // panel stack. The pointer to the original window can still be used to
 // excute most window functions with the exception of Refresh(). Always
 // use panel's Refresh() function.
 func NewPanel(w *Window) *Panel {
-	return &Panel{C.new_panel(w.win)}
+	return lookupPanel(C.new_panel(w.win))
 }
 
 // UpdatePanels refreshes the panel stack. It must be called prior to
@@ -30,16 +59,73 @@ func UpdatePanels() {
 	return
 }
 
-// Returns a pointer to the panel above in the stack or nil. Passing nil will
-// return the top panel in the stack
+// PanelAt returns the topmost visible panel whose window covers the screen
+// coordinates y, x, such as those of a MouseEvent, or nil if there is none.
+func PanelAt(y, x int) *Panel {
+	for pan := C.panel_below(nil); pan != nil; pan = C.panel_below(pan) {
+		if C.panel_hidden(pan) != C.TRUE &&
+			C.wenclose(C.panel_window(pan), C.int(y), C.int(x)) {
+			return lookupPanel(pan)
+		}
+	}
+	return nil
+}
+
+// TopPanel returns the panel at the top of the stack or nil if the stack is
+// empty
+func TopPanel() *Panel {
+	return lookupPanel(C.panel_below(nil))
+}
+
+// BottomPanel returns the panel at the bottom of the stack or nil if the
+// stack is empty
+func BottomPanel() *Panel {
+	return lookupPanel(C.panel_above(nil))
+}
+
+// PanelsTopDown returns an iterator over the visible panels in the stack,
+// starting with the top panel
+func PanelsTopDown() iter.Seq[*Panel] {
+	return func(yield func(*Panel) bool) {
+		for p := TopPanel(); p != nil && yield(p); p = p.Below() {
+		}
+	}
+}
+
+// PanelsBottomUp returns an iterator over the visible panels in the stack,
+// starting with the bottom panel, which is the order they are drawn in
+func PanelsBottomUp() iter.Seq[*Panel] {
+	return func(yield func(*Panel) bool) {
+		for p := BottomPanel(); p != nil && yield(p); p = p.Above() {
+		}
+	}
+}
+
+// Above returns the panel above this one in the stack, or nil if it is the
+// top panel or hidden. Like ncurses, a nil panel is treated as lying below
+// the stack, so that its Above is the bottom panel.
 func (p *Panel) Above() *Panel {
-	return &Panel{C.panel_above(p.pan)}
+	if p == nil {
+		return BottomPanel()
+	}
+	return lookupPanel(C.panel_above(p.pan))
+}
+
+// Below returns the panel below this one in the stack, or nil if it is the
+// bottom panel or hidden. Like ncurses, a nil panel is treated as lying
+// above the stack, so that its Below is the top panel.
+func (p *Panel) Below() *Panel {
+	if p == nil {
+		return TopPanel()
+	}
+	return lookupPanel(C.panel_below(p.pan))
 }
 
-// Returns a pointer to the panel below in the stack or nil. Passing nil will
-// return the bottom panel in the stack
+// Below returns the panel below p in the stack.
+//
+// Deprecated: use the Below method of Panel.
 func Below(p *Panel) *Panel {
-	return &Panel{C.panel_above(p.pan)}
+	return p.Below()
 }
 
 // Move the panel to the bottom of the stack.
@@ -52,9 +138,12 @@ func (p *Panel) Bottom() error {
 
 // Delete panel, removing from the stack.
 func (p *Panel) Delete() error {
+	h := C.panel_handle(p.pan)
 	if C.del_panel(p.pan) == C.ERR {
 		return errors.New("Failed to delete panel")
 	}
+	deleteHandle(uintptr(h))
+	delete(panels, p.pan)
 	p = nil
 	return nil
 }
@@ -90,6 +179,18 @@ func (p *Panel) Replace(w *Window) error {
 	return nil
 }
 
+// SetUserData attaches the value v to the panel, replacing any value
+// previously attached. The value is released when the panel is deleted.
+func (p *Panel) SetUserData(v interface{}) error {
+	old, h := C.panel_handle(p.pan), newHandle(v)
+	if C.set_panel_handle(p.pan, C.uintptr_t(h)) == C.ERR {
+		deleteHandle(h)
+		return errors.New("Failed to set panel user data")
+	}
+	deleteHandle(uintptr(old))
+	return nil
+}
+
 // Show the panel, if hidden, and place it on the top of the stack.
 func (p *Panel) Show() error {
 	if C.show_panel(p.pan) == C.ERR {
@@ -106,6 +207,11 @@ func (p *Panel) Top() error {
 	return nil
 }
 
+// UserData returns the value attached to the panel by SetUserData or nil
+func (p *Panel) UserData() interface{} {
+	return handleValue(uintptr(C.panel_handle(p.pan)))
+}
+
 // Window returns the window governed by panel
 func (p *Panel) Window() *Window {
 	return &Window{C.panel_window(p.pan)}
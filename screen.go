@@ -29,8 +29,10 @@ type Screen struct{ scrPtr *C.SCREEN }
 // allocated to it. This function is usually only useful for programs using
 // multiple terminals or test for terminal capabilites. The argument termType
 // is the type of terminal to be used ($TERM is used if value is "" which also
-// has the same effect of using os.Getenv("TERM"))
+// has the same effect of using os.Getenv("TERM")). Like Init, it sets the
+// locale from the environment when built with the ncursesw tag.
 func NewTerm(termType string, out, in *os.File) (*Screen, error) {
+	setLocale()
 	var tt, wr, rd *C.char
 	if termType == "" {
 		tt, wr, rd = (*C.char)(nil), C.CString("w"), C.CString("r")
@@ -0,0 +1,131 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Rect describes a rectangular area of a window. Note that, like the rest of
+// ncurses, it uses the idiom of y before x and height before width.
+type Rect struct {
+	Y, X int // top left corner
+	H, W int // height and width
+}
+
+type Align byte
+
+// Text alignment options for PrintAligned
+const (
+	ALIGN_LEFT Align = iota
+	ALIGN_CENTER
+	ALIGN_RIGHT
+)
+
+// cutWidth splits s after as many leading characters as will fit in width
+// columns.
+func cutWidth(s string, width int) (string, string) {
+	for i, r := range s {
+		rw := RuneWidth(r)
+		if rw > width {
+			return s[:i], s[i:]
+		}
+		width -= rw
+	}
+	return s, ""
+}
+
+// Truncate shortens text so that it fits within width columns. If the text
+// has to be shortened the tail is appended to it, for example "…" or "...",
+// and is included in the width. Display width is measured with StringWidth
+// so multi-byte and wide characters are never split.
+func Truncate(text string, width int, tail string) string {
+	if StringWidth(text) <= width {
+		return text
+	}
+	tw := StringWidth(tail)
+	if tw > width {
+		head, _ := cutWidth(tail, width)
+		return head
+	}
+	head, _ := cutWidth(text, width-tw)
+	return head + tail
+}
+
+// WrapText breaks text into lines no wider than width columns. Lines are
+// broken at white space where possible, with words longer than width split
+// across lines. Newlines in text always begin a new line. Runs of white
+// space within a line are collapsed into a single space. A character wider
+// than width is given a line of its own. Nil is returned if width is not
+// positive.
+func WrapText(text string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line, lw := "", 0
+		for _, word := range strings.Fields(para) {
+			ww := StringWidth(word)
+			if lw > 0 && lw+1+ww <= width {
+				line, lw = line+" "+word, lw+1+ww
+				continue
+			}
+			if lw > 0 {
+				lines = append(lines, line)
+			}
+			for ww > width {
+				var head string
+				head, word = cutWidth(word, width)
+				if head == "" {
+					// Too narrow for even a single character, which is
+					// given a line of its own
+					_, n := utf8.DecodeRuneInString(word)
+					if n == len(word) {
+						break
+					}
+					head, word = word[:n], word[n:]
+				}
+				lines = append(lines, head)
+				ww = StringWidth(word)
+			}
+			line, lw = word, ww
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// PrintAligned prints text within a field width columns wide which starts
+// at y, x. The text is aligned to the left, centre or right of the field
+// according to align and is cut short if it is wider than the field. Areas
+// of the field not covered by the text are left untouched.
+func (w *Window) PrintAligned(y, x, width int, align Align, text string) {
+	text = Truncate(text, width, "")
+	switch align {
+	case ALIGN_CENTER:
+		x += (width - StringWidth(text)) / 2
+	case ALIGN_RIGHT:
+		x += width - StringWidth(text)
+	}
+	w.MovePrint(y, x, text)
+}
+
+// PrintWrapped prints text within the rectangle r, wrapping it at word
+// boundaries as described by WrapText. Lines which do not fit within the
+// height of the rectangle are not printed. It returns the number of lines
+// used. Use WrapText to find how many lines the complete text requires.
+func (w *Window) PrintWrapped(r Rect, text string) int {
+	lines := WrapText(text, r.W)
+	if len(lines) > r.H {
+		lines = lines[:r.H]
+	}
+	for i, line := range lines {
+		w.MovePrint(r.Y+i, r.X, line)
+	}
+	return len(lines)
+}
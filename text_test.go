@@ -0,0 +1,34 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestWrapTextNarrow(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"日本", 1, []string{"日", "本"}},
+		{"a 日本 b", 1, []string{"a", "日", "本", "b"}},
+		{"日本語", 3, []string{"日", "本", "語"}},
+		{"abc", 0, nil},
+		{"abc", -1, nil},
+	}
+	for _, test := range tests {
+		got := goncurses.WrapText(test.text, test.width)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("WrapText(%q, %d) = %q, want %q", test.text, test.width,
+				got, test.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import "runtime/cgo"
+
+// Go values may not be stored in C memory, so the user data attached to
+// panels, menus, items and fields is stored in their C user pointers as a
+// cgo.Handle. A zero handle means no value is attached.
+
+// newHandle returns a handle for v or zero if v is nil
+func newHandle(v interface{}) uintptr {
+	if v == nil {
+		return 0
+	}
+	return uintptr(cgo.NewHandle(v))
+}
+
+// handleValue returns the value of the handle h or nil if it is zero
+func handleValue(h uintptr) interface{} {
+	if h == 0 {
+		return nil
+	}
+	return cgo.Handle(h).Value()
+}
+
+// deleteHandle releases the handle h if it is set
+func deleteHandle(h uintptr) {
+	if h != 0 {
+		cgo.Handle(h).Delete()
+	}
+}
@@ -40,6 +40,18 @@ func (w *Window) MoveAddChar(y, x int, ach Char) {
 	C.mvwaddch(w.win, C.int(y), C.int(x), C.chtype(ach))
 }
 
+// Attr returns the window's current attributes and color pair. The color
+// pair is not included in the returned attributes. If they can't be read
+// WA_NORMAL and pair 0 are returned.
+func (w *Window) Attr() (Attr, int16) {
+	var attr C.attr_t
+	var pair C.short
+	if C.wattr_get(w.win, &attr, &pair, nil) == C.ERR {
+		return WA_NORMAL, 0
+	}
+	return Attr(attr) &^ A_COLOR, int16(pair)
+}
+
 // Turn off character attribute.
 func (w *Window) AttrOff(attr Char) (err error) {
 	if C.ncurses_wattroff(w.win, C.int(attr)) == C.ERR {
@@ -98,6 +110,28 @@ func (w *Window) Box(vch, hch Char) error {
 	return nil
 }
 
+// ChangeAttr sets the attributes and color pair of n characters starting at
+// the current cursor position without altering the characters themselves.
+// A value of -1 for n changes the remainder of the line. The cursor does
+// not move. Use one or more of the WA_* attributes OR'd together.
+func (w *Window) ChangeAttr(n int, attr Attr, pair int16) error {
+	if C.wchgat(w.win, C.int(n), C.attr_t(attr), C.short(pair), nil) ==
+		C.ERR {
+		return errors.New("Failed to change attributes")
+	}
+	return nil
+}
+
+// MoveChangeAttr moves the cursor to the specified coordinates and changes
+// the attributes of n characters. See ChangeAttr for more details.
+func (w *Window) MoveChangeAttr(y, x, n int, attr Attr, pair int16) error {
+	if C.mvwchgat(w.win, C.int(y), C.int(x), C.int(n), C.attr_t(attr),
+		C.short(pair), nil) == C.ERR {
+		return errors.New("Failed to change attributes")
+	}
+	return nil
+}
+
 // Clears the screen and the underlying virtual screen. This forces the entire
 // screen to be rewritten from scratch. This will cause likely cause a
 // noticeable flicker because the screen is completely cleared before
@@ -196,12 +230,22 @@ func (w *Window) MoveDelChar(y, x int) error {
 	return nil
 }
 
+// DeleteLine deletes the line under the cursor. All lines below it are moved
+// up by one and the bottom line of the window is cleared.
+func (w *Window) DeleteLine() error {
+	if C.wdeleteln(w.win) == C.ERR {
+		return errors.New("Failed to delete line")
+	}
+	return nil
+}
+
 // Delete the window. This function must be called to ensure memory is freed
 // to prevent memory leaks once you are done with the window.
 func (w *Window) Delete() error {
 	if C.delwin(w.win) == C.ERR {
 		return errors.New("Failed to delete window")
 	}
+	delete(windowWriters, w.win)
 	w = nil
 	return nil
 }
@@ -239,6 +283,12 @@ func (w *Window) Erase() {
 // been received) the value returned will be zero (0)
 func (w *Window) GetChar() Key {
 	ch := C.wgetch(w.win)
+	for ch == keySGRMouse {
+		if w.sgrMouse() {
+			return KEY_MOUSE
+		}
+		ch = C.wgetch(w.win)
+	}
 	if ch == C.ERR {
 		ch = 0
 	}
@@ -248,7 +298,14 @@ func (w *Window) GetChar() Key {
 // MoveGetChar moves the cursor to the given position and gets a character
 // from the input stream
 func (w *Window) MoveGetChar(y, x int) Key {
-	return Key(C.mvwgetch(w.win, C.int(y), C.int(x)))
+	ch := C.mvwgetch(w.win, C.int(y), C.int(x))
+	for ch == keySGRMouse {
+		if w.sgrMouse() {
+			return KEY_MOUSE
+		}
+		ch = C.wgetch(w.win)
+	}
+	return Key(ch)
 }
 
 // GetString reads at most 'n' characters entered by the user from the Window.
@@ -287,6 +344,95 @@ func (w *Window) MoveInChar(y, x int) Char {
 	return Char(C.mvwinch(w.win, C.int(y), C.int(x)))
 }
 
+// InsertChar inserts the character before the character under the cursor.
+// All characters to the right of the cursor are moved one position to the
+// right, possibly losing the rightmost character of the line. The cursor
+// position does not change.
+func (w *Window) InsertChar(ach Char) error {
+	if C.winsch(w.win, C.chtype(ach)) == C.ERR {
+		return errors.New("Failed to insert character")
+	}
+	return nil
+}
+
+// MoveInsertChar moves the cursor to the specified coordinates and inserts
+// the character. See InsertChar for more details.
+func (w *Window) MoveInsertChar(y, x int, ach Char) error {
+	if C.mvwinsch(w.win, C.int(y), C.int(x), C.chtype(ach)) == C.ERR {
+		return errors.New("Failed to insert character")
+	}
+	return nil
+}
+
+// InsertDeleteLines inserts n blank lines above the cursor when n is
+// positive, or deletes n lines starting at the cursor when n is negative.
+// Lines moved off the bottom of the window are lost. The cursor position
+// does not change.
+func (w *Window) InsertDeleteLines(n int) error {
+	if C.winsdelln(w.win, C.int(n)) == C.ERR {
+		return errors.New("Failed to insert or delete lines")
+	}
+	return nil
+}
+
+// InsertLine inserts a blank line above the line under the cursor. The
+// bottom line of the window is lost.
+func (w *Window) InsertLine() error {
+	if C.winsertln(w.win) == C.ERR {
+		return errors.New("Failed to insert line")
+	}
+	return nil
+}
+
+// InsertString inserts the string before the character under the cursor.
+// Characters to the right of the cursor are shifted right and those moved
+// beyond the end of the line are lost. The cursor position does not change.
+func (w *Window) InsertString(str string) error {
+	cstr := C.CString(str)
+	defer C.free(unsafe.Pointer(cstr))
+
+	if C.winsstr(w.win, cstr) == C.ERR {
+		return errors.New("Failed to insert string")
+	}
+	return nil
+}
+
+// MoveInsertString moves the cursor to the specified coordinates and inserts
+// the string. See InsertString for more details.
+func (w *Window) MoveInsertString(y, x int, str string) error {
+	cstr := C.CString(str)
+	defer C.free(unsafe.Pointer(cstr))
+
+	if C.mvwinsstr(w.win, C.int(y), C.int(x), cstr) == C.ERR {
+		return errors.New("Failed to insert string")
+	}
+	return nil
+}
+
+// InsertNString behaves like InsertString but inserts at most n bytes of
+// the string. If n is negative the entire string is inserted.
+func (w *Window) InsertNString(str string, n int) error {
+	cstr := C.CString(str)
+	defer C.free(unsafe.Pointer(cstr))
+
+	if C.winsnstr(w.win, cstr, C.int(n)) == C.ERR {
+		return errors.New("Failed to insert string")
+	}
+	return nil
+}
+
+// MoveInsertNString moves the cursor to the specified coordinates and
+// inserts at most n bytes of the string. See InsertNString for more details.
+func (w *Window) MoveInsertNString(y, x int, str string, n int) error {
+	cstr := C.CString(str)
+	defer C.free(unsafe.Pointer(cstr))
+
+	if C.mvwinsnstr(w.win, C.int(y), C.int(x), cstr, C.int(n)) == C.ERR {
+		return errors.New("Failed to insert string")
+	}
+	return nil
+}
+
 // IsCleared returns the value set in ClearOk
 func (w *Window) IsCleared() bool {
 	return bool(C.ncurses_is_cleared(w.win))
@@ -297,6 +443,14 @@ func (w *Window) IsKeypad() bool {
 	return bool(C.ncurses_is_keypad(w.win))
 }
 
+// IdlOk sets whether curses may use the terminal's hardware insert and
+// delete line capabilities to update the window. This is useful for windows
+// which scroll, such as a log, but can be visually disruptive otherwise.
+// Defaults to false.
+func (w *Window) IdlOk(ok bool) {
+	C.idlok(w.win, C.bool(ok))
+}
+
 // Keypad turns on/off the keypad characters, including those like the F1-F12
 // keys and the arrow keys
 func (w *Window) Keypad(keypad bool) error {
@@ -375,9 +529,10 @@ func (w *Window) Parent() *Window {
 
 // Print a string to the given window. See the fmt package in the standard
 // library for more information. In order to simulate the 'n' version
-// of functions (like addnstr) just slice your string to the maximum
-// length before passing it as an argument.
-// window.Print("My line which should be clamped to 20 characters"[:20])
+// of functions (like addnstr) use Truncate to shorten your string to the
+// maximum width before passing it as an argument. Unlike slicing, Truncate
+// measures display width and will not split multi-byte characters.
+// window.Print(Truncate("My line which should be clamped to 20 columns", 20, ""))
 func (w *Window) Print(args ...interface{}) {
 	w.Printf("%s", fmt.Sprint(args...))
 }
@@ -435,11 +590,35 @@ func (w *Window) Scroll(n int) {
 	C.wscrl(w.win, C.int(n))
 }
 
+// ScrollRegion returns the top and bottom lines of the window's scrolling
+// region. See SetScrollRegion.
+func (w *Window) ScrollRegion() (int, int) {
+	var top, bottom C.int
+	C.ncurses_wgetscrreg(w.win, &top, &bottom)
+	return int(top), int(bottom)
+}
+
+// SetScrollRegion sets the scrolling region of the window to the lines from
+// top to bottom, inclusive. When ScrollOk has been set, moving beyond the
+// bottom line of the region, or calling Scroll, scrolls only the lines
+// within the region; the remaining lines are left untouched.
+func (w *Window) SetScrollRegion(top, bottom int) error {
+	if C.wsetscrreg(w.win, C.int(top), C.int(bottom)) == C.ERR {
+		return errors.New("Failed to set scrolling region")
+	}
+	return nil
+}
+
 // ScrollOk sets whether scrolling will work
 func (w *Window) ScrollOk(ok bool) {
 	C.scrollok(w.win, C.bool(ok))
 }
 
+// IsScrollOk returns the value set in ScrollOk
+func (w *Window) IsScrollOk() bool {
+	return bool(C.ncurses_is_scrollok(w.win))
+}
+
 // SubWindow creates a new window of height and width at the coordinates
 // y, x.  This window shares memory with the original window so changes
 // made to one window are reflected in the other. It is necessary to call
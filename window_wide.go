@@ -0,0 +1,120 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ncursesw
+
+package goncurses
+
+/*
+#include <locale.h>
+#include <stdlib.h>
+#include <wchar.h>
+#include <curses.h>
+
+static int ncurses_wins_wch(WINDOW *win, wchar_t ch) {
+	cchar_t cc;
+	wchar_t wstr[2] = { ch, L'\0' };
+	if (setcchar(&cc, wstr, A_NORMAL, 0, NULL) == ERR)
+		return ERR;
+	return wins_wch(win, &cc);
+}
+
+static int ncurses_mvwins_wch(WINDOW *win, int y, int x, wchar_t ch) {
+	if (wmove(win, y, x) == ERR)
+		return ERR;
+	return ncurses_wins_wch(win, ch);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// Wide character output depends on the locale, so Init and NewTerm use the
+// one set by the environment, as any other locale aware program would
+func init() {
+	setLocale = func() {
+		cstr := C.CString("")
+		defer C.free(unsafe.Pointer(cstr))
+
+		C.setlocale(C.LC_ALL, cstr)
+	}
+}
+
+// wideString converts str to a NUL terminated wide character string
+// suitable for passing to the wide character curses functions.
+func wideString(str string) []C.wchar_t {
+	wstr := make([]C.wchar_t, 0, len(str)+1)
+	for _, r := range str {
+		wstr = append(wstr, C.wchar_t(r))
+	}
+	return append(wstr, 0)
+}
+
+// InsertWideChar inserts the rune before the character under the cursor.
+// See InsertChar for more details. Only available when built with the
+// ncursesw tag.
+func (w *Window) InsertWideChar(r rune) error {
+	if C.ncurses_wins_wch(w.win, C.wchar_t(r)) == C.ERR {
+		return errors.New("Failed to insert character")
+	}
+	return nil
+}
+
+// MoveInsertWideChar moves the cursor to the specified coordinates and
+// inserts the rune. See InsertWideChar for more details.
+func (w *Window) MoveInsertWideChar(y, x int, r rune) error {
+	if C.ncurses_mvwins_wch(w.win, C.int(y), C.int(x), C.wchar_t(r)) ==
+		C.ERR {
+		return errors.New("Failed to insert character")
+	}
+	return nil
+}
+
+// InsertWideString inserts the string, which may contain multi-byte and
+// double width characters, before the character under the cursor. See
+// InsertString for more details. Only available when built with the
+// ncursesw tag.
+func (w *Window) InsertWideString(str string) error {
+	wstr := wideString(str)
+	if C.wins_wstr(w.win, &wstr[0]) == C.ERR {
+		return errors.New("Failed to insert string")
+	}
+	return nil
+}
+
+// MoveInsertWideString moves the cursor to the specified coordinates and
+// inserts the string. See InsertWideString for more details.
+func (w *Window) MoveInsertWideString(y, x int, str string) error {
+	wstr := wideString(str)
+	if C.mvwins_wstr(w.win, C.int(y), C.int(x), &wstr[0]) == C.ERR {
+		return errors.New("Failed to insert string")
+	}
+	return nil
+}
+
+// InsertWideNString behaves like InsertWideString but inserts at most n
+// characters (not bytes) of the string. If n is negative the entire string
+// is inserted.
+func (w *Window) InsertWideNString(str string, n int) error {
+	wstr := wideString(str)
+	if C.wins_nwstr(w.win, &wstr[0], C.int(n)) == C.ERR {
+		return errors.New("Failed to insert string")
+	}
+	return nil
+}
+
+// MoveInsertWideNString moves the cursor to the specified coordinates and
+// inserts at most n characters of the string. See InsertWideNString for
+// more details.
+func (w *Window) MoveInsertWideNString(y, x int, str string, n int) error {
+	wstr := wideString(str)
+	if C.mvwins_nwstr(w.win, C.int(y), C.int(x), &wstr[0], C.int(n)) ==
+		C.ERR {
+		return errors.New("Failed to insert string")
+	}
+	return nil
+}
@@ -0,0 +1,422 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import "errors"
+
+// Minimum size of a managed window, including its frame, leaving room for
+// the title bar buttons
+const (
+	wmMinHeight = 3
+	wmMinWidth  = 12
+)
+
+// WindowManager arranges overlapping, framed windows on the screen in the
+// manner of a desktop. Each window has a title bar, which may be dragged to
+// move the window, with buttons to minimize and close it. The bottom right
+// corner of the frame may be dragged to resize the window. Clicking on a
+// window focuses it and raises it to the top.
+//
+// Windows are built on panels, so the screen should be updated with the
+// manager's Refresh rather than by refreshing windows directly. Mouse
+// events are passed to HandleMouse and keys to HandleKey. Dragging requires
+// the mouse mask to include M_POSITION and the press and release of button
+// one; a MouseInterval of zero is recommended so that presses are reported
+// immediately.
+type WindowManager struct {
+	// Keys handled by HandleKey. NextKey focuses the next window in turn,
+	// restoring it if minimized, and PrevKey the previous window.
+	// MinimizeKey and CloseKey act on the focused window. A zero key is
+	// ignored. NextKey defaults to F6.
+	NextKey, PrevKey, MinimizeKey, CloseKey Key
+
+	// OnClose, if set, is called before a window is closed by the user.
+	// Returning false leaves the window open.
+	OnClose func(*ManagedWindow) bool
+
+	// OnResize, if set, is called after a window is resized by the user
+	// so that its contents can be redrawn.
+	OnResize func(*ManagedWindow)
+
+	windows []*ManagedWindow
+	focus   *ManagedWindow
+	drag    *ManagedWindow
+	resize  bool
+	dy, dx  int
+}
+
+// ManagedWindow is a window belonging to a WindowManager
+type ManagedWindow struct {
+	wm        *WindowManager
+	title     string
+	panel     *Panel
+	frame     *Window
+	client    *Window
+	minimized bool
+}
+
+// NewWindowManager returns a WindowManager with no windows
+func NewWindowManager() *WindowManager {
+	return &WindowManager{NextKey: KEY_F6}
+}
+
+// Open creates a new window with the given title whose frame is h lines
+// high and w columns wide with its top left corner at y, x. The new window
+// is placed on top and focused.
+func (m *WindowManager) Open(title string, y, x, h, w int) (*ManagedWindow,
+	error) {
+	if h < wmMinHeight || w < wmMinWidth {
+		return nil, errors.New("Window is too small")
+	}
+	frame, err := NewWindow(h, w, y, x)
+	if err != nil {
+		return nil, err
+	}
+	mw := &ManagedWindow{wm: m, title: title, frame: frame,
+		client: frame.Derived(h-2, w-2, 1, 1), panel: NewPanel(frame)}
+	m.windows = append(m.windows, mw)
+	m.focus = mw
+	return mw, nil
+}
+
+// Focused returns the focused window or nil if there is none
+func (m *WindowManager) Focused() *ManagedWindow {
+	return m.focus
+}
+
+// Windows returns the manager's windows in the order they were opened
+func (m *WindowManager) Windows() []*ManagedWindow {
+	return append([]*ManagedWindow(nil), m.windows...)
+}
+
+// WindowAt returns the topmost visible window at the screen coordinates y,
+// x or nil if there is none.
+func (m *WindowManager) WindowAt(y, x int) *ManagedWindow {
+	return m.lookup(PanelAt(y, x))
+}
+
+func (m *WindowManager) lookup(p *Panel) *ManagedWindow {
+	for _, mw := range m.windows {
+		if mw.panel == p {
+			return mw
+		}
+	}
+	return nil
+}
+
+// Next focuses the window opened after the focused one, wrapping around to
+// the first, restoring it if it is minimized
+func (m *WindowManager) Next() {
+	m.cycle(1)
+}
+
+// Prev focuses the window opened before the focused one, wrapping around to
+// the last, restoring it if it is minimized
+func (m *WindowManager) Prev() {
+	m.cycle(-1)
+}
+
+func (m *WindowManager) cycle(dir int) {
+	if len(m.windows) == 0 {
+		return
+	}
+	i := -1
+	if dir < 0 {
+		i = 0
+	}
+	for j, mw := range m.windows {
+		if mw == m.focus {
+			i = j
+		}
+	}
+	i = (i + dir + len(m.windows)) % len(m.windows)
+	m.windows[i].Focus()
+}
+
+// Refresh redraws the window frames and updates the screen
+func (m *WindowManager) Refresh() {
+	for _, mw := range m.windows {
+		mw.drawFrame()
+	}
+	UpdatePanels()
+	Update()
+}
+
+// HandleKey acts on the keys configured in the manager. It returns true if
+// the key was handled.
+func (m *WindowManager) HandleKey(k Key) bool {
+	if k == 0 {
+		return false
+	}
+	switch k {
+	case m.NextKey:
+		m.Next()
+	case m.PrevKey:
+		m.Prev()
+	case m.MinimizeKey:
+		if m.focus != nil {
+			m.focus.Minimize()
+		}
+	case m.CloseKey:
+		if m.focus != nil {
+			m.userClose(m.focus)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// HandleMouse focuses and raises a window clicked on and handles its title
+// bar buttons and the dragging of its title bar and resize corner. It
+// returns true if the event was handled. Clicks within a window's client
+// area focus the window but are not otherwise handled, so that they can be
+// acted on by the caller.
+func (m *WindowManager) HandleMouse(ev *MouseEvent) bool {
+	if ev == nil {
+		return false
+	}
+	if m.drag != nil {
+		switch ev.Action {
+		case MOUSE_DRAGGED, MOUSE_MOVED:
+			m.dragTo(ev.Y, ev.X)
+			return true
+		case MOUSE_RELEASED, MOUSE_CLICKED:
+			if ev.Button == 1 {
+				m.dragTo(ev.Y, ev.X)
+				m.drag = nil
+				return true
+			}
+		}
+	}
+	if ev.Button != 1 || ev.Action != MOUSE_PRESSED &&
+		ev.Action != MOUSE_CLICKED {
+		return false
+	}
+	mw := m.WindowAt(ev.Y, ev.X)
+	if mw == nil {
+		return false
+	}
+	mw.Focus()
+	y, x, _ := mw.frame.MouseToLocal(ev)
+	h, w := mw.frame.MaxYX()
+	switch {
+	case y == 0 && x >= w-8 && x <= w-6:
+		mw.Minimize()
+	case y == 0 && x >= w-5 && x <= w-3:
+		m.userClose(mw)
+	case y == 0 && ev.Action == MOUSE_PRESSED:
+		m.drag, m.resize, m.dy, m.dx = mw, false, y, x
+	case y == h-1 && x == w-1 && ev.Action == MOUSE_PRESSED:
+		m.drag, m.resize, m.dy, m.dx = mw, true, y, x
+	default:
+		return y == 0 || y == h-1 || x == 0 || x == w-1
+	}
+	return true
+}
+
+// dragTo moves or resizes the window being dragged so that the point on its
+// frame which was grabbed lies under the pointer at y, x
+func (m *WindowManager) dragTo(y, x int) {
+	mw := m.drag
+	top, left := mw.frame.YX()
+	if m.resize {
+		h, w := y-top+1, x-left+1
+		if h < wmMinHeight {
+			h = wmMinHeight
+		}
+		if w < wmMinWidth {
+			w = wmMinWidth
+		}
+		if oh, ow := mw.frame.MaxYX(); (h != oh || w != ow) &&
+			mw.Resize(h, w) == nil && m.OnResize != nil {
+			m.OnResize(mw)
+		}
+		return
+	}
+	mw.Move(y-m.dy, x-m.dx)
+}
+
+func (m *WindowManager) userClose(mw *ManagedWindow) {
+	if m.OnClose == nil || m.OnClose(mw) {
+		mw.Close()
+	}
+}
+
+// focusTop focuses the topmost visible window, if any
+func (m *WindowManager) focusTop() {
+	m.focus = nil
+	for p := range PanelsTopDown() {
+		if mw := m.lookup(p); mw != nil {
+			m.focus = mw
+			return
+		}
+	}
+}
+
+// Window returns the window for the client area of the managed window,
+// inside its frame. The client window is replaced when the managed window
+// is resized.
+func (mw *ManagedWindow) Window() *Window {
+	return mw.client
+}
+
+// Panel returns the panel holding the managed window's frame
+func (mw *ManagedWindow) Panel() *Panel {
+	return mw.panel
+}
+
+// Title returns the window's title
+func (mw *ManagedWindow) Title() string {
+	return mw.title
+}
+
+// SetTitle sets the title shown in the window's title bar
+func (mw *ManagedWindow) SetTitle(title string) {
+	mw.title = title
+}
+
+// Focus raises the window to the top and gives it focus, restoring it if
+// minimized
+func (mw *ManagedWindow) Focus() {
+	if mw.minimized {
+		mw.Restore()
+		return
+	}
+	mw.panel.Top()
+	mw.wm.focus = mw
+}
+
+// Focused returns true if the window has focus
+func (mw *ManagedWindow) Focused() bool {
+	return mw.wm.focus == mw
+}
+
+// Minimize hides the window. If it had focus, the topmost remaining window
+// is focused.
+func (mw *ManagedWindow) Minimize() error {
+	if err := mw.panel.Hide(); err != nil {
+		return err
+	}
+	mw.minimized = true
+	if mw.wm.drag == mw {
+		mw.wm.drag = nil
+	}
+	if mw.wm.focus == mw {
+		mw.wm.focusTop()
+	}
+	return nil
+}
+
+// Minimized returns true if the window is minimized
+func (mw *ManagedWindow) Minimized() bool {
+	return mw.minimized
+}
+
+// Restore shows a minimized window on top of the others and focuses it
+func (mw *ManagedWindow) Restore() error {
+	if err := mw.panel.Show(); err != nil {
+		return err
+	}
+	mw.minimized = false
+	mw.wm.focus = mw
+	return nil
+}
+
+// Move moves the window's frame so that its top left corner is at y, x.
+// The window is kept within the screen.
+func (mw *ManagedWindow) Move(y, x int) error {
+	sh, sw := StdScr().MaxYX()
+	h, w := mw.frame.MaxYX()
+	y, x = clamp(y, 0, sh-h), clamp(x, 0, sw-w)
+	if err := mw.panel.Move(y, x); err != nil {
+		return err
+	}
+	// Moving a window doesn't move its derived windows along with it
+	mw.client.MoveWindow(y+1, x+1)
+	return nil
+}
+
+// Resize changes the size of the window's frame to h lines by w columns,
+// keeping as much of the client window's contents as fits. The frame and
+// client windows are replaced by new windows of the new size.
+func (mw *ManagedWindow) Resize(h, w int) error {
+	if h < wmMinHeight || w < wmMinWidth {
+		return errors.New("Window is too small")
+	}
+	y, x := mw.frame.YX()
+	sh, sw := StdScr().MaxYX()
+	if h > sh-y {
+		h = sh - y
+	}
+	if w > sw-x {
+		w = sw - x
+	}
+	frame, err := NewWindow(h, w, y, x)
+	if err != nil {
+		return err
+	}
+	client := frame.Derived(h-2, w-2, 1, 1)
+	ch, cw := mw.client.MaxYX()
+	client.Copy(mw.client, 0, 0, 0, 0, min(ch, h-2)-1, min(cw, w-2)-1,
+		false)
+	if err := mw.panel.Replace(frame); err != nil {
+		client.Delete()
+		frame.Delete()
+		return err
+	}
+	mw.client.Delete()
+	mw.frame.Delete()
+	mw.frame, mw.client = frame, client
+	return nil
+}
+
+// Close removes the window from the screen and deletes it. If it had focus,
+// the topmost remaining window is focused.
+func (mw *ManagedWindow) Close() error {
+	m := mw.wm
+	for i, w := range m.windows {
+		if w == mw {
+			m.windows = append(m.windows[:i], m.windows[i+1:]...)
+			break
+		}
+	}
+	if m.drag == mw {
+		m.drag = nil
+	}
+	err := mw.panel.Delete()
+	mw.client.Delete()
+	mw.frame.Delete()
+	if m.focus == mw {
+		m.focusTop()
+	}
+	return err
+}
+
+// drawFrame draws the window's border, with a double line if it has focus,
+// and its title bar
+func (mw *ManagedWindow) drawFrame() {
+	h, w := mw.frame.MaxYX()
+	style := BORDER_SINGLE
+	if mw.Focused() {
+		style = BORDER_DOUBLE
+	}
+	mw.frame.DrawBox(Rect{0, 0, h, w}, style)
+	if title := Truncate(mw.title, w-11, "..."); title != "" {
+		mw.frame.MovePrint(0, 1, " "+title+" ")
+	}
+	mw.frame.MovePrint(0, w-8, "[_][x]")
+}
+
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}